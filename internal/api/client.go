@@ -2,7 +2,9 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -14,30 +16,101 @@ type Client struct {
 	BaseURL    string
 	APIKey     string
 	HTTPClient *http.Client
+	Limiter    *Limiter // nil sends requests without limiting
+
+	// Cache answers repeated chat requests without sending them; optional
+	Cache ResponseCache
+	// CacheOnly fails chat requests that are not in Cache with ErrCacheMiss
+	CacheOnly bool
+}
+
+// ResponseCache stores chat completion responses by request
+type ResponseCache interface {
+	Get(req *ChatCompletionRequest) (*ChatCompletionResponse, bool)
+	Put(req *ChatCompletionRequest, resp *ChatCompletionResponse) error
+}
+
+// ErrCacheMiss is returned for uncached requests when CacheOnly is set
+var ErrCacheMiss = errors.New("response is not in the cache")
+
+// ErrNotRecorded is wrapped by a replaying transport, such as a cassette
+// replayer, for a request it has no recorded response for
+var ErrNotRecorded = errors.New("no recorded response")
+
+// defaultTransport is the transport of clients from NewClient; nil means
+// http.DefaultTransport
+var defaultTransport http.RoundTripper
+
+// SetDefaultTransport sets the transport used by clients created with
+// NewClient from then on, such as a cassette recorder
+func SetDefaultTransport(transport http.RoundTripper) {
+	defaultTransport = transport
 }
 
 // NewClient creates a new OpenRouter API client
 func NewClient(baseURL, apiKey string, timeout int) *Client {
 	httpClient := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
+		Timeout:   time.Duration(timeout) * time.Second,
+		Transport: defaultTransport,
 	}
 
 	return &Client{
 		BaseURL:    baseURL,
 		APIKey:     apiKey,
 		HTTPClient: httpClient,
+		Limiter:    defaultLimiter,
+	}
+}
+
+// send sends req once the limiter allows a request expected to use tokens,
+// and lets the limiter see the response's rate limit headers
+func (c *Client) send(req *http.Request, tokens int) (*http.Response, error) {
+	if c.Limiter == nil {
+		return c.HTTPClient.Do(req)
+	}
+	c.Limiter.Wait(tokens)
+	resp, err := c.HTTPClient.Do(req)
+	if err == nil {
+		c.Limiter.Observe(resp.StatusCode, resp.Header)
+	}
+	return resp, err
+}
+
+// estimateTokens guesses the tokens a request will use: about four
+// characters per prompt token, plus the most the reply may use
+func estimateTokens(req *ChatCompletionRequest) int {
+	chars := 0
+	for _, m := range req.Messages {
+		chars += len(m.Content)
 	}
+	return chars/4 + req.MaxTokens
 }
 
 // SendChatCompletion sends a chat completion request to the API
 func (c *Client) SendChatCompletion(req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
-	url := fmt.Sprintf("%s/chat/completions", c.BaseURL)
-
 	// Marshal request to JSON
 	body, err := json.Marshal(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
+	return c.SendChatCompletionBody(req, body)
+}
+
+// SendChatCompletionBody is SendChatCompletion for a request that is
+// already encoded as body, which is sent as it is; req must decode from
+// body and is used for the cache and the rate limiter
+func (c *Client) SendChatCompletionBody(req *ChatCompletionRequest, body []byte) (*ChatCompletionResponse, error) {
+	if c.Cache != nil {
+		if cached, ok := c.Cache.Get(req); ok {
+			cached.Cached = true
+			return cached, nil
+		}
+	}
+	if c.CacheOnly {
+		return nil, ErrCacheMiss
+	}
+
+	url := fmt.Sprintf("%s/chat/completions", c.BaseURL)
 
 	// Create HTTP request
 	httpReq, err := http.NewRequest("POST", url, bytes.NewReader(body))
@@ -52,7 +125,8 @@ func (c *Client) SendChatCompletion(req *ChatCompletionRequest) (*ChatCompletion
 	httpReq.Header.Set("X-Title", "OpenRouter CLI")
 
 	// Send request
-	resp, err := c.HTTPClient.Do(httpReq)
+	estimate := estimateTokens(req)
+	resp, err := c.send(httpReq, estimate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -66,7 +140,9 @@ func (c *Client) SendChatCompletion(req *ChatCompletionRequest) (*ChatCompletion
 
 	// Check for HTTP errors
 	if resp.StatusCode >= 400 {
-		return nil, parseAPIError(resp.StatusCode, respBody)
+		apiErr := parseAPIError(resp.StatusCode, respBody)
+		apiErr.RetryAfter = retryAfter(resp.Header, time.Now())
+		return nil, apiErr
 	}
 
 	// Unmarshal response
@@ -74,10 +150,54 @@ func (c *Client) SendChatCompletion(req *ChatCompletionRequest) (*ChatCompletion
 	if err := json.Unmarshal(respBody, &chatResp); err != nil {
 		return nil, fmt.Errorf("failed to parse response: %w", err)
 	}
+	if c.Limiter != nil && chatResp.Usage.TotalTokens > 0 {
+		c.Limiter.Adjust(chatResp.Usage.TotalTokens - estimate)
+	}
+	if c.Cache != nil && len(chatResp.Choices) > 0 {
+		// A response that can't be cached is still a good response
+		_ = c.Cache.Put(req, &chatResp)
+	}
 
 	return &chatResp, nil
 }
 
+// ForwardChatCompletion sends a chat completion request body as it is, for
+// requests with fields ChatCompletionRequest doesn't have, such as stream or
+// tools. The response is returned unread, whatever its status, and the
+// caller must close its body. Streamed responses aren't cut off by the
+// client's timeout; cancel ctx to stop them.
+func (c *Client) ForwardChatCompletion(ctx context.Context, body []byte, stream bool) (*http.Response, error) {
+	if c.CacheOnly {
+		return nil, ErrCacheMiss
+	}
+	url := fmt.Sprintf("%s/chat/completions", c.BaseURL)
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("HTTP-Referer", "https://github.com/kdevrou/openrouter-cli")
+	httpReq.Header.Set("X-Title", "OpenRouter CLI")
+
+	client := c
+	if stream && c.HTTPClient.Timeout > 0 {
+		httpClient := *c.HTTPClient
+		httpClient.Timeout = 0
+		client = &Client{HTTPClient: &httpClient, Limiter: c.Limiter}
+	}
+	// The typed fields are enough to estimate the tokens for the limiter.
+	// The body is sent as it is whatever it holds, so a failed or partial
+	// decode only makes the estimate lower.
+	var req ChatCompletionRequest
+	_ = json.Unmarshal(body, &req)
+	resp, err := client.send(httpReq, estimateTokens(&req))
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	return resp, nil
+}
+
 // ListModels fetches the list of available models
 func (c *Client) ListModels() ([]Model, error) {
 	url := fmt.Sprintf("%s/models", c.BaseURL)
@@ -94,7 +214,7 @@ func (c *Client) ListModels() ([]Model, error) {
 	req.Header.Set("X-Title", "OpenRouter CLI")
 
 	// Send request
-	resp, err := c.HTTPClient.Do(req)
+	resp, err := c.send(req, 0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
@@ -120,8 +240,51 @@ func (c *Client) ListModels() ([]Model, error) {
 	return modelsResp.Data, nil
 }
 
+// GetKeyInfo fetches the label, usage and limit of the client's API key
+// It fails with a 401 APIError when the key is invalid
+func (c *Client) GetKeyInfo() (*KeyInfo, error) {
+	url := fmt.Sprintf("%s/key", c.BaseURL)
+
+	// Create HTTP request
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	// Set headers
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.APIKey))
+	req.Header.Set("HTTP-Referer", "https://github.com/kdevrou/openrouter-cli")
+	req.Header.Set("X-Title", "OpenRouter CLI")
+
+	// Send request
+	resp, err := c.send(req, 0)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Read response body
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// Check for HTTP errors
+	if resp.StatusCode >= 400 {
+		return nil, parseAPIError(resp.StatusCode, respBody)
+	}
+
+	// Unmarshal response
+	var keyResp KeyInfoResponse
+	if err := json.Unmarshal(respBody, &keyResp); err != nil {
+		return nil, fmt.Errorf("failed to parse response: %w", err)
+	}
+
+	return &keyResp.Data, nil
+}
+
 // parseAPIError parses an error response from the API
-func parseAPIError(statusCode int, body []byte) error {
+func parseAPIError(statusCode int, body []byte) *APIError {
 	var errorResp map[string]interface{}
 	if err := json.Unmarshal(body, &errorResp); err != nil {
 		// If we can't parse as JSON, return a generic error
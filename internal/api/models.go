@@ -1,5 +1,11 @@
 package api
 
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
 // Message represents a chat message
 type Message struct {
 	Role    string `json:"role"` // "user", "assistant", "system"
@@ -10,8 +16,20 @@ type Message struct {
 type ChatCompletionRequest struct {
 	Model       string    `json:"model"`
 	Messages    []Message `json:"messages"`
-	Temperature float64   `json:"temperature,omitempty"`
+	Temperature *float64  `json:"temperature,omitempty"` // nil leaves it to the provider; 0 is sent
 	MaxTokens   int       `json:"max_tokens,omitempty"`
+
+	Provider *ProviderPreferences `json:"provider,omitempty"`
+}
+
+// ProviderPreferences controls how OpenRouter routes a request across providers
+type ProviderPreferences struct {
+	Order          []string `json:"order,omitempty"`
+	AllowFallbacks *bool    `json:"allow_fallbacks,omitempty"`
+	Only           []string `json:"only,omitempty"`
+	Ignore         []string `json:"ignore,omitempty"`
+	DataCollection string   `json:"data_collection,omitempty"`
+	Sort           string   `json:"sort,omitempty"`
 }
 
 // Choice represents a completion choice in the response
@@ -36,6 +54,9 @@ type ChatCompletionResponse struct {
 	Model   string   `json:"model"`
 	Choices []Choice `json:"choices"`
 	Usage   Usage    `json:"usage"`
+
+	// Cached is set when the response came from the client's cache
+	Cached bool `json:"cached,omitempty"`
 }
 
 // ModelPricing contains pricing information for a model
@@ -44,6 +65,31 @@ type ModelPricing struct {
 	Completion string `json:"completion"`
 }
 
+// Cost returns the price in dollars of a request with the given usage
+// It reports false when the price is unknown or depends on the routed model.
+func (p ModelPricing) Cost(usage Usage) (float64, bool) {
+	prompt, err := parsePrice(p.Prompt)
+	if err != nil {
+		return 0, false
+	}
+	completion, err := parsePrice(p.Completion)
+	if err != nil {
+		return 0, false
+	}
+	if prompt < 0 || completion < 0 {
+		return 0, false
+	}
+	return float64(usage.PromptTokens)*prompt + float64(usage.CompletionTokens)*completion, true
+}
+
+// parsePrice parses a per-token price, treating an empty price as free
+func parsePrice(price string) (float64, error) {
+	if price == "" {
+		return 0, nil
+	}
+	return strconv.ParseFloat(price, 64)
+}
+
 // Architecture contains architectural information about a model
 type Architecture struct {
 	Modality     string `json:"modality"`
@@ -68,11 +114,25 @@ type ModelsResponse struct {
 	Data []Model `json:"data"`
 }
 
+// KeyInfo describes the API key used for a request
+type KeyInfo struct {
+	Label      string   `json:"label"`
+	Usage      float64  `json:"usage"`
+	Limit      *float64 `json:"limit"` // nil when the key has no credit limit
+	IsFreeTier bool     `json:"is_free_tier"`
+}
+
+// KeyInfoResponse is the response from the key info endpoint
+type KeyInfoResponse struct {
+	Data KeyInfo `json:"data"`
+}
+
 // APIError represents an error from the OpenRouter API
 type APIError struct {
 	StatusCode int
 	Message    string
 	Type       string
+	RetryAfter time.Duration // From the response's Retry-After header; 0 when absent
 }
 
 func (e *APIError) Error() string {
@@ -81,3 +141,23 @@ func (e *APIError) Error() string {
 	}
 	return e.Message
 }
+
+// IsRetryable reports whether the same request may succeed if sent again:
+// rate limits, timeouts and server-side failures
+func (e *APIError) IsRetryable() bool {
+	return e.StatusCode == 408 || e.StatusCode == 429 || e.StatusCode >= 500
+}
+
+// IsProviderUnavailable reports whether the error means no provider could
+// serve the model, as opposed to a problem with the request or the key
+func (e *APIError) IsProviderUnavailable() bool {
+	switch e.StatusCode {
+	case 502, 503:
+		return true
+	case 404:
+		return strings.Contains(strings.ToLower(e.Message), "no endpoints")
+	}
+	msg := strings.ToLower(e.Message)
+	return strings.Contains(msg, "no endpoints found") || strings.Contains(msg, "provider returned error") ||
+		strings.Contains(msg, "no allowed providers")
+}
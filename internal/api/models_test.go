@@ -0,0 +1,32 @@
+package api
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestChatCompletionRequestTemperature(t *testing.T) {
+	zero, warm := 0.0, 0.7
+	tests := []struct {
+		temperature *float64
+		want        string
+	}{
+		{&zero, `"temperature":0,`},
+		{&warm, `"temperature":0.7,`},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		data, err := json.Marshal(&ChatCompletionRequest{Model: "m", Temperature: tt.temperature, MaxTokens: 100})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tt.want == "" {
+			if strings.Contains(string(data), "temperature") {
+				t.Errorf("unset temperature was sent: %s", data)
+			}
+		} else if !strings.Contains(string(data), tt.want) {
+			t.Errorf("request %s doesn't contain %s", data, tt.want)
+		}
+	}
+}
@@ -0,0 +1,188 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Clock is the time source for Limiter; tests can substitute a fake one
+type Clock interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+// realClock is the system clock
+type realClock struct{}
+
+func (realClock) Now() time.Time        { return time.Now() }
+func (realClock) Sleep(d time.Duration) { time.Sleep(d) }
+
+// bucket is a token bucket refilled continuously at perMinute per minute
+// Reservations may take it below zero; callers then wait until it recovers,
+// which keeps waiting callers in order.
+type bucket struct {
+	perMinute float64 // 0 means unlimited
+	available float64
+	last      time.Time
+}
+
+// setRate changes the bucket's rate, starting it full
+func (b *bucket) setRate(perMinute int, now time.Time) {
+	b.perMinute = float64(perMinute)
+	b.available = b.perMinute
+	b.last = now
+}
+
+func (b *bucket) refill(now time.Time) {
+	if b.perMinute == 0 {
+		return
+	}
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.available = min(b.perMinute, b.available+elapsed.Minutes()*b.perMinute)
+	}
+	b.last = now
+}
+
+// reserve takes n from the bucket and returns how long to wait before using them
+func (b *bucket) reserve(n float64, now time.Time) time.Duration {
+	if b.perMinute == 0 {
+		return 0
+	}
+	b.refill(now)
+	b.available -= n
+	if b.available >= 0 {
+		return 0
+	}
+	return time.Duration(-b.available / b.perMinute * float64(time.Minute))
+}
+
+// Limiter spaces out API requests to stay under requests-per-minute and
+// tokens-per-minute limits. It also pauses every caller when the API
+// reports that the key's limit is exhausted.
+type Limiter struct {
+	mu          sync.Mutex
+	clock       Clock
+	requests    bucket
+	tokens      bucket
+	pausedUntil time.Time
+}
+
+// NewLimiter creates a limiter; a zero limit is not enforced
+// A nil clock uses the system clock.
+func NewLimiter(requestsPerMinute, tokensPerMinute int, clock Clock) *Limiter {
+	if clock == nil {
+		clock = realClock{}
+	}
+	l := &Limiter{clock: clock}
+	l.SetLimits(requestsPerMinute, tokensPerMinute)
+	return l
+}
+
+// defaultLimiter is shared by every Client from NewClient, so concurrent
+// requests in one process draw on the same limits
+var defaultLimiter = NewLimiter(0, 0, nil)
+
+// DefaultLimiter returns the limiter shared by clients created with NewClient
+func DefaultLimiter() *Limiter {
+	return defaultLimiter
+}
+
+// SetLimits changes the limits; a zero limit is not enforced
+func (l *Limiter) SetLimits(requestsPerMinute, tokensPerMinute int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+	l.requests.setRate(requestsPerMinute, now)
+	l.tokens.setRate(tokensPerMinute, now)
+}
+
+// Wait blocks until a request expected to use tokens may be sent
+func (l *Limiter) Wait(tokens int) {
+	l.mu.Lock()
+	now := l.clock.Now()
+	wait := max(l.requests.reserve(1, now), l.tokens.reserve(float64(tokens), now), l.pausedUntil.Sub(now))
+	l.mu.Unlock()
+
+	if wait > 0 {
+		l.clock.Sleep(wait)
+	}
+}
+
+// Adjust corrects the token count after a response reports the real usage
+// A positive delta means more tokens were used than Wait was told.
+func (l *Limiter) Adjust(delta int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tokens.perMinute == 0 {
+		return
+	}
+	l.tokens.refill(l.clock.Now())
+	l.tokens.available = min(l.tokens.perMinute, l.tokens.available-float64(delta))
+}
+
+// Observe adapts to the rate limit headers on a response
+// X-RateLimit-Remaining caps the requests left in the bucket, and when it
+// reaches zero every request waits for X-RateLimit-Reset. A 429 with
+// Retry-After pauses requests for that long.
+func (l *Limiter) Observe(statusCode int, header http.Header) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.clock.Now()
+
+	if statusCode == http.StatusTooManyRequests {
+		if wait := retryAfter(header, now); wait > 0 {
+			l.pauseUntil(now.Add(wait))
+		}
+	}
+
+	remaining, err := strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	if err != nil {
+		return
+	}
+	if l.requests.perMinute > 0 {
+		l.requests.refill(now)
+		l.requests.available = min(l.requests.available, float64(remaining))
+	}
+	if remaining <= 0 {
+		if reset, ok := parseReset(header.Get("X-RateLimit-Reset"), now); ok {
+			l.pauseUntil(reset)
+		}
+	}
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP
+// date, returning 0 when it is absent or invalid
+func retryAfter(header http.Header, now time.Time) time.Duration {
+	value := header.Get("Retry-After")
+	if seconds, err := strconv.Atoi(value); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
+
+func (l *Limiter) pauseUntil(t time.Time) {
+	if t.After(l.pausedUntil) {
+		l.pausedUntil = t
+	}
+}
+
+// parseReset parses X-RateLimit-Reset, which may be a Unix time in
+// milliseconds or seconds, or a number of seconds from now
+func parseReset(value string, now time.Time) (time.Time, bool) {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n <= 0 {
+		return time.Time{}, false
+	}
+	switch {
+	case n > 1e12:
+		return time.UnixMilli(n), true
+	case n > 1e9:
+		return time.Unix(n, 0), true
+	}
+	return now.Add(time.Duration(n) * time.Second), true
+}
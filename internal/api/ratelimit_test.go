@@ -0,0 +1,223 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose Sleep advances Now instead of blocking
+type fakeClock struct {
+	now    time.Time
+	sleeps []time.Duration
+}
+
+func newFakeClock() *fakeClock {
+	return &fakeClock{now: time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)}
+}
+
+func (c *fakeClock) Now() time.Time { return c.now }
+
+func (c *fakeClock) Sleep(d time.Duration) {
+	c.sleeps = append(c.sleeps, d)
+	c.now = c.now.Add(d)
+}
+
+func (c *fakeClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// waited returns the sleeps since the last call
+func (c *fakeClock) waited() []time.Duration {
+	sleeps := c.sleeps
+	c.sleeps = nil
+	return sleeps
+}
+
+func assertWaits(t *testing.T, clock *fakeClock, want ...time.Duration) {
+	t.Helper()
+	got := clock.waited()
+	if len(got) != len(want) {
+		t.Fatalf("waits = %v, want %v", got, want)
+	}
+	for i := range got {
+		if diff := got[i] - want[i]; diff < -time.Millisecond || diff > time.Millisecond {
+			t.Fatalf("waits = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestLimiterUnlimited(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(0, 0, clock)
+	for range 1000 {
+		l.Wait(100000)
+	}
+	assertWaits(t, clock)
+}
+
+func TestLimiterRequestsPerMinute(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(60, 0, clock)
+	for range 60 {
+		l.Wait(0)
+	}
+	assertWaits(t, clock)
+
+	// The bucket is empty, so each further request waits for one to refill
+	l.Wait(0)
+	assertWaits(t, clock, time.Second)
+	l.Wait(0)
+	assertWaits(t, clock, time.Second)
+}
+
+func TestLimiterRefill(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(60, 0, clock)
+	for range 60 {
+		l.Wait(0)
+	}
+	clock.advance(30 * time.Second)
+	for range 30 {
+		l.Wait(0)
+	}
+	assertWaits(t, clock)
+	l.Wait(0)
+	assertWaits(t, clock, time.Second)
+
+	// Refilling never goes past the limit
+	clock.advance(time.Hour)
+	for range 60 {
+		l.Wait(0)
+	}
+	assertWaits(t, clock)
+	l.Wait(0)
+	assertWaits(t, clock, time.Second)
+}
+
+func TestLimiterTokensPerMinute(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(0, 1000, clock)
+	l.Wait(800)
+	assertWaits(t, clock)
+
+	// 200 tokens short at 1000 a minute
+	l.Wait(400)
+	assertWaits(t, clock, 12*time.Second)
+}
+
+func TestLimiterWaitsForSlowerLimit(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(60, 600, clock)
+	l.Wait(600)
+	assertWaits(t, clock)
+
+	// One request is available, but the tokens take 10s to come back
+	l.Wait(100)
+	assertWaits(t, clock, 10*time.Second)
+}
+
+func TestLimiterAdjust(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(0, 1000, clock)
+
+	// The response used 900 more tokens than estimated
+	l.Wait(100)
+	l.Adjust(900)
+	l.Wait(100)
+	assertWaits(t, clock, 6*time.Second)
+
+	// Returning unused tokens lets the next request go at once
+	clock.advance(time.Minute)
+	l.Wait(1000)
+	l.Adjust(-500)
+	l.Wait(500)
+	assertWaits(t, clock)
+
+	// Adjusting never fills the bucket past the limit
+	clock.advance(time.Minute)
+	l.Adjust(-5000)
+	l.Wait(1000)
+	l.Wait(100)
+	assertWaits(t, clock, 6*time.Second)
+}
+
+func TestLimiterAdjustUnlimited(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(0, 0, clock)
+	l.Adjust(100000)
+	l.Wait(100)
+	assertWaits(t, clock)
+}
+
+func TestLimiterObserveRetryAfter(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(0, 0, clock)
+	header := http.Header{"Retry-After": {"5"}}
+
+	l.Observe(http.StatusOK, header)
+	l.Wait(0)
+	assertWaits(t, clock)
+
+	l.Observe(http.StatusTooManyRequests, header)
+	l.Wait(0)
+	assertWaits(t, clock, 5*time.Second)
+	l.Wait(0)
+	assertWaits(t, clock)
+
+	header.Set("Retry-After", clock.Now().Add(8*time.Second).Format(http.TimeFormat))
+	l.Observe(http.StatusTooManyRequests, header)
+	l.Wait(0)
+	assertWaits(t, clock, 8*time.Second)
+}
+
+func TestLimiterObserveRemaining(t *testing.T) {
+	clock := newFakeClock()
+	l := NewLimiter(60, 0, clock)
+
+	// The API says 2 requests are left: the third waits for a refill
+	l.Observe(http.StatusOK, http.Header{"X-Ratelimit-Remaining": {"2"}})
+	l.Wait(0)
+	l.Wait(0)
+	assertWaits(t, clock)
+	l.Wait(0)
+	assertWaits(t, clock, time.Second)
+
+	// None left: wait until the reset time
+	l.Observe(http.StatusOK, http.Header{"X-Ratelimit-Remaining": {"0"}, "X-Ratelimit-Reset": {"20"}})
+	l.Wait(0)
+	assertWaits(t, clock, 20*time.Second)
+}
+
+func TestClientCallsShareLimiter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "3")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	clock := newFakeClock()
+	client := NewClient(server.URL, "key", 10)
+	client.Limiter = NewLimiter(0, 0, clock)
+
+	for name, call := range map[string]func() error{
+		"ListModels": func() error { _, err := client.ListModels(); return err },
+		"GetKeyInfo": func() error { _, err := client.GetKeyInfo(); return err },
+		"SendChatCompletion": func() error {
+			_, err := client.SendChatCompletion(&ChatCompletionRequest{Model: "m", Messages: []Message{{Role: "user", Content: "hi"}}})
+			var apiErr *APIError
+			if errors.As(err, &apiErr) && apiErr.RetryAfter != 3*time.Second {
+				t.Errorf("SendChatCompletion RetryAfter = %v, want 3s", apiErr.RetryAfter)
+			}
+			return err
+		},
+	} {
+		if err := call(); err == nil {
+			t.Fatalf("%s: expected an error", name)
+		}
+		// The 429 was seen by the limiter, so the next request waits
+		client.Limiter.Wait(0)
+		assertWaits(t, clock, 3*time.Second)
+	}
+}
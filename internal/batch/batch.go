@@ -0,0 +1,325 @@
+// Package batch runs many chat completion requests read from JSONL, with
+// bounded concurrency and retries
+package batch
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// maxLineSize is the longest input or output line that can be read
+const maxLineSize = 16 * 1024 * 1024
+
+// Item is one request from the input file
+// Either Prompt or Messages must be set. Items without an ID are numbered
+// by their line in the input.
+type Item struct {
+	ID          string        `json:"id,omitempty"`
+	Prompt      string        `json:"prompt,omitempty"`
+	System      string        `json:"system,omitempty"`
+	Messages    []api.Message `json:"messages,omitempty"`
+	Model       string        `json:"model,omitempty"`
+	Temperature *float64      `json:"temperature,omitempty"`
+	MaxTokens   *int          `json:"max_tokens,omitempty"`
+}
+
+// ChatMessages returns the item's messages, building them from System and
+// Prompt when Messages is not set
+func (it Item) ChatMessages() []api.Message {
+	if len(it.Messages) > 0 {
+		return it.Messages
+	}
+	var messages []api.Message
+	if it.System != "" {
+		messages = append(messages, api.Message{Role: "system", Content: it.System})
+	}
+	return append(messages, api.Message{Role: "user", Content: it.Prompt})
+}
+
+// Result is one line of the output file
+type Result struct {
+	ID           string     `json:"id"`
+	Model        string     `json:"model,omitempty"`
+	Response     string     `json:"response,omitempty"`
+	FinishReason string     `json:"finish_reason,omitempty"`
+	Usage        *api.Usage `json:"usage,omitempty"`
+	Cost         *float64   `json:"cost,omitempty"` // Dollars, when the model's price is known
+	Cached       bool       `json:"cached,omitempty"`
+	Error        string     `json:"error,omitempty"`
+	Attempts     int        `json:"attempts"`
+	DurationMS   int64      `json:"duration_ms"`
+}
+
+// ReadItems parses JSONL input, skipping blank lines
+// Every line is checked before any request is sent, so a bad line is
+// reported with its line number up front.
+func ReadItems(r io.Reader) ([]Item, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	var items []Item
+	seen := make(map[string]int)
+	var problems []string
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		var item Item
+		decoder := json.NewDecoder(strings.NewReader(text))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&item); err != nil {
+			problems = append(problems, fmt.Sprintf("line %d: %v", line, err))
+			continue
+		}
+		if item.ID == "" {
+			item.ID = strconv.Itoa(line)
+		}
+		switch {
+		case item.Prompt == "" && len(item.Messages) == 0:
+			problems = append(problems, fmt.Sprintf("line %d: needs a prompt or messages", line))
+		case item.Prompt != "" && len(item.Messages) > 0:
+			problems = append(problems, fmt.Sprintf("line %d: has both prompt and messages", line))
+		case seen[item.ID] > 0:
+			problems = append(problems, fmt.Sprintf("line %d: id %q is already used on line %d", line, item.ID, seen[item.ID]))
+		}
+		seen[item.ID] = line
+		items = append(items, item)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read input: %w", err)
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid input:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return items, nil
+}
+
+// ReadDone returns the IDs recorded in an existing output file, so a rerun
+// can skip them. Failed results are left out when retryFailed is set. A
+// missing file has no IDs.
+func ReadDone(path string, retryFailed bool) (map[string]bool, error) {
+	done := make(map[string]bool)
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+		var result Result
+		if err := json.Unmarshal(scanner.Bytes(), &result); err != nil {
+			// A run that was killed mid-write can leave a partial last line
+			continue
+		}
+		// A retried ID may have a failure followed by a success
+		if result.Error == "" || !retryFailed {
+			done[result.ID] = true
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return done, nil
+}
+
+// OpenOutput opens an output file for appending results. When a killed run
+// left a partial last line, a newline is written first, so the next result
+// starts on its own line instead of being joined to the fragment.
+func OpenOutput(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if info.Size() > 0 {
+		last := make([]byte, 1)
+		if _, err := f.ReadAt(last, info.Size()-1); err != nil {
+			f.Close()
+			return nil, err
+		}
+		if last[0] != '\n' {
+			if _, err := f.Write([]byte{'\n'}); err != nil {
+				f.Close()
+				return nil, err
+			}
+		}
+	}
+	return f, nil
+}
+
+// Sender sends a chat completion request; *api.Client implements it
+type Sender interface {
+	SendChatCompletion(req *api.ChatCompletionRequest) (*api.ChatCompletionResponse, error)
+}
+
+// Runner processes items with a fixed number of workers
+type Runner struct {
+	Client      Sender
+	Concurrency int
+	Retries     int           // Extra attempts after a retryable failure
+	Backoff     time.Duration // Wait before the first retry, doubled after each
+
+	// Request builds the request for an item
+	Request func(Item) (*api.ChatCompletionRequest, error)
+	// Cost prices a response, returning false when the price is unknown; optional
+	Cost func(model string, usage api.Usage) (float64, bool)
+	// Sleep waits between retries; it defaults to time.Sleep
+	Sleep func(time.Duration)
+	// Now is the clock used to time requests; it defaults to time.Now
+	Now func() time.Time
+}
+
+// Run sends every item and calls emit with each result as it finishes
+// emit is never called concurrently. Run stops early only if emit fails.
+func (r *Runner) Run(items []Item, emit func(Result) error) error {
+	workers := max(r.Concurrency, 1)
+	jobs := make(chan Item)
+	results := make(chan Result)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for item := range jobs {
+				results <- r.process(item)
+			}
+		}()
+	}
+
+	stop := make(chan struct{})
+	go func() {
+		defer close(jobs)
+		for _, item := range items {
+			select {
+			case jobs <- item:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var emitErr error
+	for result := range results {
+		if emitErr != nil {
+			continue
+		}
+		if emitErr = emit(result); emitErr != nil {
+			close(stop)
+		}
+	}
+	return emitErr
+}
+
+// process sends one item, retrying retryable failures
+func (r *Runner) process(item Item) Result {
+	now := r.Now
+	if now == nil {
+		now = time.Now
+	}
+	sleep := r.Sleep
+	if sleep == nil {
+		sleep = time.Sleep
+	}
+
+	result := Result{ID: item.ID}
+	start := now()
+
+	req, err := r.Request(item)
+	if err != nil {
+		result.Error = err.Error()
+		result.DurationMS = now().Sub(start).Milliseconds()
+		return result
+	}
+	result.Model = req.Model
+
+	backoff := r.Backoff
+	for {
+		result.Attempts++
+		resp, err := r.Client.SendChatCompletion(req)
+		if err == nil {
+			r.fill(&result, resp)
+			break
+		}
+		result.Error = err.Error()
+		if !retryable(err) || result.Attempts > r.Retries {
+			break
+		}
+		// A rate limit that says when to come back overrides the backoff
+		wait := backoff
+		var apiErr *api.APIError
+		if errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+			wait = apiErr.RetryAfter
+		}
+		sleep(wait)
+		backoff *= 2
+	}
+	result.DurationMS = now().Sub(start).Milliseconds()
+	return result
+}
+
+// fill records a successful response in result
+func (r *Runner) fill(result *Result, resp *api.ChatCompletionResponse) {
+	result.Error = ""
+	if resp.Model != "" {
+		result.Model = resp.Model
+	}
+	if len(resp.Choices) == 0 {
+		result.Error = "no choices in response"
+		return
+	}
+	result.Response = resp.Choices[0].Message.Content
+	result.FinishReason = resp.Choices[0].FinishReason
+	result.Cached = resp.Cached
+	usage := resp.Usage
+	result.Usage = &usage
+	// A cached answer cost nothing this time
+	if r.Cost != nil && !resp.Cached {
+		if cost, ok := r.Cost(result.Model, usage); ok {
+			result.Cost = &cost
+		}
+	}
+}
+
+// retryable reports whether a failed request is worth sending again
+// API errors are retried for rate limits and server failures; other errors
+// are network failures, which are retried too. A cache miss with
+// --cache-only, or a request missing from a cassette, never succeeds.
+func retryable(err error) bool {
+	if errors.Is(err, api.ErrCacheMiss) || errors.Is(err, api.ErrNotRecorded) {
+		return false
+	}
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.IsRetryable()
+	}
+	return true
+}
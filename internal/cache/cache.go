@@ -0,0 +1,278 @@
+// Package cache stores chat completion responses on disk, keyed by a hash
+// of the request, with an expiry time and a size cap
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// entryExt is the extension of cached response files
+const entryExt = ".json"
+
+// now is the clock used for expiry; tests can replace it
+var now = time.Now
+
+// Cache is a directory of cached responses, one file per request
+// A file's modification time records when it was last used, so the least
+// recently used responses are removed first when the cache is over MaxSize.
+type Cache struct {
+	Dir     string
+	TTL     time.Duration // How long a response is reused; 0 for no expiry
+	MaxSize int64         // Largest total size of the files; 0 for no limit
+	Scope   string        // Mixed into every key, such as the API base URL
+
+	mu sync.Mutex // Serializes eviction between a process's requests
+}
+
+// New returns a cache in dir
+func New(dir string, ttl time.Duration, maxSize int64) *Cache {
+	return &Cache{Dir: dir, TTL: ttl, MaxSize: maxSize}
+}
+
+// ForBaseURL returns a cache in dir whose responses are kept apart from
+// those of other API base URLs, so answers from a mock or local server are
+// never served for requests to OpenRouter
+func ForBaseURL(dir string, ttl time.Duration, maxSize int64, baseURL string) *Cache {
+	c := New(dir, ttl, maxSize)
+	c.Scope = strings.TrimRight(baseURL, "/")
+	return c
+}
+
+// entry is the content of a cached response file
+type entry struct {
+	Created  time.Time                   `json:"created"`
+	Scope    string                      `json:"scope,omitempty"`
+	Request  *api.ChatCompletionRequest  `json:"request"`
+	Response *api.ChatCompletionResponse `json:"response"`
+}
+
+// Key returns the hash identifying a request in scope: the SHA-256 of the
+// scope and the request's JSON, which covers the model, messages,
+// parameters and provider routing
+func Key(scope string, req *api.ChatCompletionRequest) string {
+	data, _ := json.Marshal(req)
+	sum := sha256.Sum256(append([]byte(scope+"\n"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+// path returns the file for key, in a subdirectory named by its first two
+// characters to keep directories small
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+entryExt)
+}
+
+// Get returns the cached response to req, if there is one that hasn't expired
+func (c *Cache) Get(req *api.ChatCompletionRequest) (*api.ChatCompletionResponse, bool) {
+	path := c.path(Key(c.Scope, req))
+	e, err := readEntry(path)
+	if err != nil {
+		return nil, false
+	}
+	if c.expired(e) {
+		os.Remove(path)
+		return nil, false
+	}
+	t := now()
+	os.Chtimes(path, t, t)
+	return e.Response, true
+}
+
+// Put stores the response to req, then removes the least recently used
+// responses if the cache is over its size cap
+func (c *Cache) Put(req *api.ChatCompletionRequest, resp *api.ChatCompletionResponse) error {
+	data, err := json.Marshal(entry{Created: now(), Scope: c.Scope, Request: req, Response: resp})
+	if err != nil {
+		return err
+	}
+	path := c.path(Key(c.Scope, req))
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	// Write then rename, so a concurrent Get never sees half a file
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return c.evict()
+}
+
+func (c *Cache) expired(e *entry) bool {
+	return c.TTL > 0 && now().Sub(e.Created) > c.TTL
+}
+
+// file is a cached response file found by walking the cache
+type file struct {
+	path    string
+	size    int64
+	lastUse time.Time
+}
+
+// files lists every cached response file
+func (c *Cache) files() ([]file, error) {
+	var files []file
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), entryExt) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			// Removed by another process since the directory was read
+			return nil
+		}
+		files = append(files, file{path: path, size: info.Size(), lastUse: info.ModTime()})
+		return nil
+	})
+	return files, err
+}
+
+// evict removes the least recently used files until the cache fits MaxSize
+func (c *Cache) evict() error {
+	if c.MaxSize <= 0 {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files, err := c.files()
+	if err != nil {
+		return err
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].lastUse.Before(files[j].lastUse) })
+	for _, f := range files {
+		if total <= c.MaxSize {
+			break
+		}
+		if err := os.Remove(f.path); err == nil || errors.Is(err, fs.ErrNotExist) {
+			total -= f.size
+		}
+	}
+	return nil
+}
+
+// Stats describes what is in the cache
+type Stats struct {
+	Dir     string    `json:"dir"`
+	Entries int       `json:"entries"`
+	Expired int       `json:"expired"`
+	Size    int64     `json:"size"`
+	MaxSize int64     `json:"max_size"`
+	TTL     string    `json:"ttl"`
+	Oldest  time.Time `json:"oldest,omitzero"`
+	Newest  time.Time `json:"newest,omitzero"`
+}
+
+// Stats counts the cached responses and their total size
+func (c *Cache) Stats() (*Stats, error) {
+	stats := &Stats{Dir: c.Dir, MaxSize: c.MaxSize, TTL: formatTTL(c.TTL)}
+	files, err := c.files()
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range files {
+		e, err := readEntry(f.path)
+		if err != nil {
+			continue
+		}
+		stats.Entries++
+		stats.Size += f.size
+		if c.expired(e) {
+			stats.Expired++
+		}
+		if stats.Oldest.IsZero() || e.Created.Before(stats.Oldest) {
+			stats.Oldest = e.Created
+		}
+		if e.Created.After(stats.Newest) {
+			stats.Newest = e.Created
+		}
+	}
+	return stats, nil
+}
+
+// Clear removes cached responses, or only the expired ones, and returns
+// how many were removed
+func (c *Cache) Clear(expiredOnly bool) (int, error) {
+	files, err := c.files()
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for _, f := range files {
+		if expiredOnly {
+			// Unreadable files are removed too; they can never be used
+			if e, err := readEntry(f.path); err == nil && !c.expired(e) {
+				continue
+			}
+		}
+		if err := os.Remove(f.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// formatTTL shows a TTL without zero minutes and seconds, as in "24h"
+func formatTTL(d time.Duration) string {
+	if d <= 0 {
+		return "none"
+	}
+	s := d.String()
+	if strings.HasSuffix(s, "m0s") {
+		s = strings.TrimSuffix(s, "0s")
+	}
+	if strings.HasSuffix(s, "h0m") {
+		s = strings.TrimSuffix(s, "0m")
+	}
+	return s
+}
+
+func readEntry(path string) (*entry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return nil, err
+	}
+	if e.Response == nil {
+		return nil, errors.New("no response")
+	}
+	return &e, nil
+}
@@ -0,0 +1,173 @@
+package cache
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// setNow replaces the clock for the rest of the test and returns a function
+// that moves it on
+func setNow(t *testing.T, start time.Time) func(time.Duration) {
+	t.Helper()
+	current := start
+	now = func() time.Time { return current }
+	t.Cleanup(func() { now = time.Now })
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func request(prompt string) *api.ChatCompletionRequest {
+	return &api.ChatCompletionRequest{Model: "openai/gpt-4", Messages: []api.Message{{Role: "user", Content: prompt}}}
+}
+
+func response(content string) *api.ChatCompletionResponse {
+	return &api.ChatCompletionResponse{
+		ID:      "gen-" + content,
+		Choices: []api.Choice{{Message: api.Message{Role: "assistant", Content: content}}},
+	}
+}
+
+func put(t *testing.T, c *Cache, prompt string) {
+	t.Helper()
+	if err := c.Put(request(prompt), response(prompt)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func has(c *Cache, prompt string) bool {
+	resp, ok := c.Get(request(prompt))
+	return ok && resp.Choices[0].Message.Content == prompt
+}
+
+func TestGetPut(t *testing.T) {
+	c := New(t.TempDir(), 0, 0)
+	if has(c, "hi") {
+		t.Fatal("empty cache returned a response")
+	}
+	put(t, c, "hi")
+	if !has(c, "hi") {
+		t.Error("stored response not found")
+	}
+
+	// Any difference in the request is a different entry
+	warm := 0.5
+	req := request("hi")
+	req.Temperature = &warm
+	if _, ok := c.Get(req); ok {
+		t.Error("a request with another temperature matched")
+	}
+}
+
+func TestTTL(t *testing.T) {
+	advance := setNow(t, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	c := New(t.TempDir(), 24*time.Hour, 0)
+	put(t, c, "hi")
+
+	advance(23 * time.Hour)
+	if !has(c, "hi") {
+		t.Fatal("response expired before its TTL")
+	}
+	stats, err := c.Stats()
+	if err != nil || stats.Entries != 1 || stats.Expired != 0 {
+		t.Fatalf("stats = %+v, %v", stats, err)
+	}
+
+	advance(2 * time.Hour)
+	stats, _ = c.Stats()
+	if stats.Expired != 1 {
+		t.Errorf("expired = %d, want 1", stats.Expired)
+	}
+	if has(c, "hi") {
+		t.Error("expired response was returned")
+	}
+	if stats, _ = c.Stats(); stats.Entries != 0 {
+		t.Errorf("expired response wasn't removed: %+v", stats)
+	}
+
+	// No TTL keeps responses forever
+	c.TTL = 0
+	put(t, c, "forever")
+	advance(365 * 24 * time.Hour)
+	if !has(c, "forever") {
+		t.Error("response expired without a TTL")
+	}
+}
+
+func TestEvictLeastRecentlyUsed(t *testing.T) {
+	advance := setNow(t, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	dir := t.TempDir()
+	c := New(dir, 0, 0)
+	put(t, c, "aaaa")
+	put(t, c, "bbbb")
+
+	// Put leaves the file's real modification time; set it to the fake clock
+	for i, prompt := range []string{"aaaa", "bbbb"} {
+		when := now().Add(time.Duration(i) * time.Minute)
+		path := c.path(Key(c.Scope, request(prompt)))
+		if err := os.Chtimes(path, when, when); err != nil {
+			t.Fatal(err)
+		}
+	}
+	stats, err := c.Stats()
+	if err != nil || stats.Entries != 2 {
+		t.Fatalf("stats = %+v, %v", stats, err)
+	}
+
+	// Reading aaaa makes bbbb the least recently used
+	advance(time.Hour)
+	if !has(c, "aaaa") {
+		t.Fatal("aaaa missing")
+	}
+
+	// Room for two entries of this size, not three
+	c.MaxSize = stats.Size + stats.Size/4
+	put(t, c, "cccc")
+	for prompt, want := range map[string]bool{"aaaa": true, "bbbb": false, "cccc": true} {
+		if got := has(c, prompt); got != want {
+			t.Errorf("%s cached = %v, want %v", prompt, got, want)
+		}
+	}
+	if stats, _ := c.Stats(); stats.Size > c.MaxSize {
+		t.Errorf("size %d is over the cap %d", stats.Size, c.MaxSize)
+	}
+}
+
+func TestScopeSeparatesBaseURLs(t *testing.T) {
+	dir := t.TempDir()
+	openrouter := ForBaseURL(dir, 0, 0, "https://openrouter.ai/api/v1/")
+	mock := ForBaseURL(dir, 0, 0, "http://127.0.0.1:8080/api/v1")
+	put(t, mock, "hi")
+
+	if has(openrouter, "hi") {
+		t.Error("a mock server's response was served for OpenRouter")
+	}
+	if !has(mock, "hi") {
+		t.Error("response missing from its own scope")
+	}
+	if !has(ForBaseURL(dir, 0, 0, "http://127.0.0.1:8080/api/v1/"), "hi") {
+		t.Error("a trailing slash changed the scope")
+	}
+	if Key("a", request("hi")) == Key("b", request("hi")) {
+		t.Error("keys in different scopes are equal")
+	}
+}
+
+func TestClearExpiredOnly(t *testing.T) {
+	advance := setNow(t, time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC))
+	c := New(t.TempDir(), time.Hour, 0)
+	put(t, c, "old")
+	advance(2 * time.Hour)
+	put(t, c, "new")
+
+	if n, err := c.Clear(true); err != nil || n != 1 {
+		t.Fatalf("Clear(true) = %d, %v; want 1", n, err)
+	}
+	if !has(c, "new") {
+		t.Error("unexpired response was cleared")
+	}
+	if n, err := c.Clear(false); err != nil || n != 1 {
+		t.Fatalf("Clear(false) = %d, %v; want 1", n, err)
+	}
+}
@@ -0,0 +1,306 @@
+// Package cassette records HTTP interactions to a directory and replays
+// them, so scripts around the CLI can be tested without the network
+package cassette
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// ErrNotRecorded is wrapped by the error for a request missing from a
+// cassette; it is api.ErrNotRecorded, so API callers can check for it
+// without depending on this package
+var ErrNotRecorded = api.ErrNotRecorded
+
+// scrubbedHeaders are never written to a cassette
+var scrubbedHeaders = []string{"Authorization", "Cookie", "Set-Cookie"}
+
+// scrubbed replaces the value of a scrubbed header
+const scrubbed = "[scrubbed]"
+
+// Cassette is one file: a request and the responses it got, in order
+// A request sent several times (for example when retried after a 429) is
+// answered with each recorded response in turn, then the last one again.
+type Cassette struct {
+	Request   Request    `json:"request"`
+	Responses []Response `json:"responses"`
+}
+
+// Request is a recorded request; a JSON body is stored as JSON, anything
+// else as a string
+type Request struct {
+	Method string          `json:"method"`
+	Path   string          `json:"path"`
+	Header http.Header     `json:"header,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+}
+
+// Response is a recorded response
+// Streamed responses such as server-sent events are stored as their full
+// body and replayed as one stream.
+type Response struct {
+	Status int             `json:"status"`
+	Header http.Header     `json:"header,omitempty"`
+	JSON   json.RawMessage `json:"json,omitempty"`
+	Body   string          `json:"body,omitempty"`
+}
+
+// normalize returns a canonical form of a body: JSON is re-encoded with
+// sorted keys and no extra whitespace, anything else is left as it is
+func normalize(body []byte) ([]byte, bool) {
+	var v any
+	if len(bytes.TrimSpace(body)) == 0 || json.Unmarshal(body, &v) != nil {
+		return body, false
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return body, false
+	}
+	return data, true
+}
+
+// key identifies a request by its method, path and normalized body
+func key(method, path string, body []byte) string {
+	normalized, _ := normalize(body)
+	sum := sha256.Sum256([]byte(method + " " + path + "\n" + string(normalized)))
+	return hex.EncodeToString(sum[:8])
+}
+
+// fileName is the cassette file for a request, e.g.
+// post-api-v1-chat-completions-1a2b3c4d5e6f7a8b.json
+func fileName(method, path string, body []byte) string {
+	slug := strings.ToLower(method + "-" + strings.ReplaceAll(strings.Trim(path, "/"), "/", "-"))
+	return slug + "-" + key(method, path, body) + ".json"
+}
+
+// readBody reads a request's body and puts back a copy for the transport
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// scrub copies header without the scrubbed headers' values
+func scrub(header http.Header) http.Header {
+	out := header.Clone()
+	for _, name := range scrubbedHeaders {
+		if out.Get(name) != "" {
+			out.Set(name, scrubbed)
+		}
+	}
+	return out
+}
+
+// Recorder sends requests with Transport and records every response to Dir
+// A request's file is rewritten the first time it is seen in a run, so
+// recording again replaces old responses rather than adding to them.
+type Recorder struct {
+	Dir       string
+	Transport http.RoundTripper // nil uses http.DefaultTransport
+
+	mu      sync.Mutex
+	started map[string]bool // Files already rewritten in this run
+}
+
+// NewRecorder returns a recorder writing to dir, creating it if needed
+func NewRecorder(dir string) (*Recorder, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cassette directory: %w", err)
+	}
+	return &Recorder{Dir: dir}, nil
+}
+
+// RoundTrip sends req and records the response as its body is read, so
+// streamed responses still reach the caller as they arrive
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	transport := r.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		// Network failures have no response to record
+		return nil, err
+	}
+
+	request := Request{Method: req.Method, Path: req.URL.Path, Header: scrub(req.Header)}
+	setBody(&request.JSON, &request.Body, body)
+	status, header := resp.StatusCode, scrub(resp.Header)
+	name := fileName(req.Method, req.URL.Path, body)
+	resp.Body = &teeBody{ReadCloser: resp.Body, done: func(data []byte) {
+		response := Response{Status: status, Header: header}
+		setBody(&response.JSON, &response.Body, data)
+		if err := r.save(name, request, response); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record %s %s: %v\n", request.Method, request.Path, err)
+		}
+	}}
+	return resp, nil
+}
+
+// setBody stores a body as JSON when it is JSON, and as a string otherwise
+func setBody(jsonField *json.RawMessage, stringField *string, body []byte) {
+	if normalized, ok := normalize(body); ok {
+		*jsonField = normalized
+		return
+	}
+	*stringField = string(body)
+}
+
+// save adds a response to a request's file
+func (r *Recorder) save(name string, request Request, response Response) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.started == nil {
+		r.started = make(map[string]bool)
+	}
+
+	path := filepath.Join(r.Dir, name)
+	c := Cassette{Request: request}
+	if r.started[name] {
+		existing, err := readCassette(path)
+		if err != nil {
+			return err
+		}
+		c.Responses = existing.Responses
+	}
+	r.started[name] = true
+	c.Responses = append(c.Responses, response)
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0644)
+}
+
+// teeBody keeps a copy of everything read from a response body and hands it
+// to done once, at the end of the body or when it is closed
+type teeBody struct {
+	io.ReadCloser
+	buf  bytes.Buffer
+	done func([]byte)
+	once sync.Once
+}
+
+func (b *teeBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.buf.Write(p[:n])
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *teeBody) Close() error {
+	b.finish()
+	return b.ReadCloser.Close()
+}
+
+func (b *teeBody) finish() {
+	b.once.Do(func() { b.done(b.buf.Bytes()) })
+}
+
+// Replayer answers requests from the cassettes in Dir without the network
+type Replayer struct {
+	Dir string
+
+	mu   sync.Mutex
+	sent map[string]int // Times each file's request has been answered
+}
+
+// NewReplayer returns a replayer reading from dir, which must exist
+func NewReplayer(dir string) (*Replayer, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cassette directory %s: %w", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("cassette directory %s is not a directory", dir)
+	}
+	return &Replayer{Dir: dir}, nil
+}
+
+// RoundTrip returns the recorded response to req, or an error wrapping
+// ErrNotRecorded when there is none
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	name := fileName(req.Method, req.URL.Path, body)
+	c, err := readCassette(filepath.Join(r.Dir, name))
+	if errors.Is(err, os.ErrNotExist) || (err == nil && len(c.Responses) == 0) {
+		return nil, fmt.Errorf("%w for %s %s in %s (expected %s); record it with --record",
+			ErrNotRecorded, req.Method, req.URL.Path, r.Dir, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	if r.sent == nil {
+		r.sent = make(map[string]int)
+	}
+	i := min(r.sent[name], len(c.Responses)-1)
+	r.sent[name]++
+	r.mu.Unlock()
+
+	recorded := c.Responses[i]
+	data := []byte(recorded.Body)
+	if len(recorded.JSON) > 0 {
+		data = recorded.JSON
+	}
+	header := recorded.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	// JSON bodies are stored normalized, so the recorded length may not match
+	header.Del("Content-Length")
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+		StatusCode:    recorded.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(data)),
+		ContentLength: int64(len(data)),
+		Request:       req,
+	}, nil
+}
+
+func readCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
@@ -0,0 +1,187 @@
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// newUpstream starts a server that answers each request with the next of
+// responses, repeating the last, and sets a session cookie
+// A response may start with a status code, as in "429 {...}".
+func newUpstream(t *testing.T, responses ...string) *httptest.Server {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body := responses[min(calls, len(responses)-1)]
+		calls++
+		w.Header().Set("Set-Cookie", "session=upstream-secret")
+		if code, rest, ok := strings.Cut(body, " "); ok {
+			if status, err := strconv.Atoi(code); err == nil {
+				w.WriteHeader(status)
+				body = rest
+			}
+		}
+		io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// do sends a request through transport and returns the status and body
+func do(t *testing.T, transport http.RoundTripper, method, url, body string) (int, string, error) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer sk-or-secret-key")
+	req.Header.Set("Cookie", "session=client-secret")
+	resp, err := (&http.Client{Transport: transport}).Do(req)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp.StatusCode, string(data), nil
+}
+
+// record sends each body to url through a new Recorder and returns its directory
+func record(t *testing.T, url string, bodies ...string) string {
+	t.Helper()
+	dir := filepath.Join(t.TempDir(), "cassettes")
+	recorder, err := NewRecorder(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, body := range bodies {
+		if _, _, err := do(t, recorder, http.MethodPost, url, body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func newReplayer(t *testing.T, dir string) *Replayer {
+	t.Helper()
+	replayer, err := NewReplayer(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return replayer
+}
+
+func TestRecordAndReplay(t *testing.T) {
+	upstream := newUpstream(t, `{"id":"gen-1","choices":[{"message":{"content":"hi"}}]}`)
+	url := upstream.URL + "/api/v1/chat/completions"
+	dir := record(t, url, `{"model":"m","messages":[{"role":"user","content":"hello"}]}`)
+	upstream.Close()
+
+	files, err := os.ReadDir(dir)
+	if err != nil || len(files) != 1 {
+		t.Fatalf("cassette files = %v, %v; want one", files, err)
+	}
+	data, err := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, secret := range []string{"sk-or-secret-key", "client-secret", "upstream-secret"} {
+		if strings.Contains(string(data), secret) {
+			t.Errorf("cassette contains %q:\n%s", secret, data)
+		}
+	}
+	if !strings.Contains(string(data), scrubbed) {
+		t.Errorf("cassette doesn't mark scrubbed headers:\n%s", data)
+	}
+
+	// Key order and whitespace don't matter, and the network isn't used
+	status, body, err := do(t, newReplayer(t, dir), http.MethodPost, url,
+		"{\n  \"messages\": [ {\"content\": \"hello\", \"role\": \"user\"} ],\n  \"model\": \"m\"\n}")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stored JSON is reformatted, so compare it normalized
+	if got, _ := normalize([]byte(body)); status != http.StatusOK || string(got) != `{"choices":[{"message":{"content":"hi"}}],"id":"gen-1"}` {
+		t.Errorf("replayed %d %s", status, body)
+	}
+}
+
+func TestReplayResponsesInOrder(t *testing.T) {
+	upstream := newUpstream(t, `429 {"error":{"message":"slow down"}}`, `{"id":"gen-2"}`)
+	url := upstream.URL + "/chat/completions"
+	request := `{"model":"m"}`
+	dir := record(t, url, request, request)
+
+	replayer := newReplayer(t, dir)
+	for i, want := range []int{429, 200, 200} {
+		status, _, err := do(t, replayer, http.MethodPost, url, request)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if status != want {
+			t.Errorf("replay %d: status %d, want %d", i+1, status, want)
+		}
+	}
+
+	// Recording again replaces the old responses
+	dir2 := record(t, url, request)
+	if status, _, _ := do(t, newReplayer(t, dir2), http.MethodPost, url, request); status != 200 {
+		t.Errorf("re-recorded status %d, want 200", status)
+	}
+}
+
+func TestRecordAndReplayStream(t *testing.T) {
+	var events strings.Builder
+	for _, word := range []string{"one", "two", "three"} {
+		fmt.Fprintf(&events, "data: {\"choices\":[{\"delta\":{\"content\":%q}}]}\n\n", word)
+	}
+	events.WriteString("data: [DONE]\n\n")
+
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range strings.SplitAfter(events.String(), "\n\n") {
+			io.WriteString(w, event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	defer upstream.Close()
+
+	url := upstream.URL + "/chat/completions"
+	request := `{"model":"m","stream":true}`
+	dir := record(t, url, request)
+	upstream.Close()
+
+	status, body, err := do(t, newReplayer(t, dir), http.MethodPost, url, request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != http.StatusOK || body != events.String() {
+		t.Errorf("replayed stream %d:\n%q\nwant:\n%q", status, body, events.String())
+	}
+}
+
+func TestReplayNotRecorded(t *testing.T) {
+	_, _, err := do(t, newReplayer(t, t.TempDir()), http.MethodGet, "http://example.invalid/api/v1/models", "")
+	if !errors.Is(err, ErrNotRecorded) {
+		t.Fatalf("err = %v, want ErrNotRecorded", err)
+	}
+	if !strings.Contains(err.Error(), "GET /api/v1/models") {
+		t.Errorf("error %q doesn't name the request", err)
+	}
+}
+
+func TestNewReplayerMissingDir(t *testing.T) {
+	if _, err := NewReplayer(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing directory")
+	}
+}
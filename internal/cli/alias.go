@@ -0,0 +1,159 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage model aliases",
+	Long: `Manage short names for model IDs.
+
+An alias can be used anywhere a model ID is accepted, such as 'chat -m' and
+'config set default_model'. Aliases may point at other aliases; cycles are
+rejected.
+
+Examples:
+  openrouter alias add fast google/gemini-flash-1.5
+  openrouter alias add sonnet anthropic/claude-3.5-sonnet:beta
+  openrouter alias add default sonnet
+  openrouter chat -m fast "Hello"
+  openrouter alias list
+  openrouter alias rm fast`,
+}
+
+var aliasAddCmd = &cobra.Command{
+	Use:   "add <name> <model>",
+	Short: "Add or replace an alias",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		name, target := args[0], args[1]
+		if err := cfg.SetAlias(name, target); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Added alias %s -> %s%s\n", name, target, describeResolved(cfg, target))
+		return nil
+	},
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:               "rm <name>",
+	Aliases:           []string{"remove"},
+	Short:             "Remove an alias",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeAliases,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		name := args[0]
+		if err := cfg.RemoveAlias(name); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Removed alias %s\n", name)
+		if cfg.DefaultModel == name {
+			fmt.Printf("Note: default_model is still %q; set it with 'openrouter config set default_model'\n", name)
+		}
+		return nil
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List aliases",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.Load()
+		if err != nil && err != config.ErrNoAPIKey {
+			PrintError(err.Error())
+			return err
+		}
+		if cfg == nil {
+			cfg = config.DefaultConfig()
+		}
+		printConfigWarnings(cfg)
+
+		names := cfg.AliasNames()
+		if len(names) == 0 {
+			fmt.Println("No aliases configured.")
+			return nil
+		}
+
+		width := 0
+		for _, name := range names {
+			width = max(width, len(name))
+		}
+		for _, name := range names {
+			target := cfg.Aliases[name]
+			fmt.Printf("%-*s  %s%s\n", width, name, target, describeResolved(cfg, target))
+		}
+		return nil
+	},
+}
+
+// resolveModel expands a model alias, printing the error when it can't be resolved
+func resolveModel(cfg *config.Config, name string) (string, error) {
+	resolved, err := cfg.ResolveModel(name)
+	if err != nil {
+		PrintError(err.Error())
+		return "", err
+	}
+	if debug && resolved != name {
+		fmt.Fprintf(os.Stderr, "Resolved model alias %s to %s\n", name, resolved)
+	}
+	return resolved, nil
+}
+
+// describeResolved shows where an alias chain ends when target is itself an alias
+func describeResolved(cfg *config.Config, target string) string {
+	resolved, err := cfg.ResolveModel(target)
+	if err != nil {
+		return fmt.Sprintf(" (%v)", err)
+	}
+	if resolved == target {
+		return ""
+	}
+	return " (-> " + resolved + ")"
+}
+
+// completeAliases completes alias names from the effective config
+func completeAliases(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return cfg.AliasNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasAddCmd)
+	aliasCmd.AddCommand(aliasRmCmd)
+	aliasCmd.AddCommand(aliasListCmd)
+}
@@ -0,0 +1,53 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/kdevrou/openrouter-cli/internal/markdown"
+	"github.com/kdevrou/openrouter-cli/internal/util"
+)
+
+// attachFiles reads the files matched by patterns and appends them to prompt
+// as labeled fenced blocks. Skipped files and the estimated size of the
+// combined prompt are reported on stderr.
+func attachFiles(prompt string, patterns []string, maxSize int64) (string, error) {
+	files, skipped, err := util.ReadFiles(patterns, maxSize)
+	if err != nil {
+		return "", err
+	}
+	for _, s := range skipped {
+		fmt.Fprintf(os.Stderr, "Skipping %s (%s)\n", s.Path, s.Reason)
+	}
+	if len(files) == 0 {
+		return "", fmt.Errorf("no readable files to attach")
+	}
+
+	var b strings.Builder
+	b.WriteString(prompt)
+	for _, f := range files {
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		b.WriteString(fileBlock(f))
+	}
+	prompt = b.String()
+	fmt.Fprintf(os.Stderr, "Attached %d file(s); prompt is %s, about %d tokens\n",
+		len(files), util.FormatSize(int64(len(prompt))), util.EstimateTokens(prompt))
+	return prompt, nil
+}
+
+// fileBlock wraps a file in a fenced block labeled with its path and language
+// The fence is made longer than any backtick run in the file so it can't end early
+func fileBlock(f util.FileInput) string {
+	fence := "```"
+	for strings.Contains(f.Content, fence) {
+		fence += "`"
+	}
+	content := f.Content
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	return fmt.Sprintf("File: %s\n%s%s\n%s%s", f.Path, fence, markdown.LangForFile(f.Path), content, fence)
+}
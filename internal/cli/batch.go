@@ -0,0 +1,260 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/batch"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Batch command flags
+	batchModel       string
+	batchTemperature float64
+	batchMaxTokens   int
+	batchConcurrency int
+	batchRetries     int
+	batchOut         string
+	batchRetryFailed bool
+)
+
+var batchCmd = &cobra.Command{
+	Use:   "batch <input.jsonl>",
+	Short: "Run many prompts from a JSONL file",
+	Long: `Send every request in a JSONL file and write the results as JSONL.
+
+Each input line is a JSON object with a prompt or a messages array, and
+optionally an id, system prompt, model, temperature and max_tokens:
+
+  {"id": "q1", "prompt": "What is Go?"}
+  {"id": "q2", "system": "Answer in French.", "prompt": "What is Go?", "model": "fast"}
+  {"id": "q3", "messages": [{"role": "user", "content": "Hi"}], "temperature": 0}
+
+Lines without an id are identified by their line number. Values missing from
+a line come from -m, -t and --max-tokens, then the config defaults. Every line
+is checked before any request is sent. Use - to read from stdin.
+
+Each output line has the id, model, response, finish_reason, usage, cost in
+dollars (when the model's price is known), error, attempts and duration_ms.
+Lines are written as requests finish, so they are not in input order.
+
+Rate limits, timeouts, server errors and network failures are retried with
+exponential backoff, or after the Retry-After of a rate-limited response.
+With --out, IDs already in the output file are skipped,
+so an interrupted run can be resumed by running the same command again;
+--retry-failed also re-runs IDs whose recorded result is an error.
+
+Examples:
+  openrouter batch prompts.jsonl --out results.jsonl
+  openrouter batch prompts.jsonl --out results.jsonl -j 8 -m fast
+  openrouter batch prompts.jsonl --out results.jsonl --retry-failed
+  jq -c '{id, prompt: .question}' questions.json | openrouter batch - > results.jsonl`,
+	Args: cobra.ExactArgs(1),
+	RunE: runBatch,
+}
+
+func runBatch(cmd *cobra.Command, args []string) error {
+	cfg, err := GetConfig()
+	if err == config.ErrNoAPIKey {
+		PrintSetupError()
+		return err
+	} else if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	if batchConcurrency < 1 {
+		PrintError("--concurrency must be at least 1")
+		return fmt.Errorf("invalid flags")
+	}
+	if batchRetryFailed && batchOut == "" {
+		PrintError("--retry-failed requires --out")
+		return fmt.Errorf("invalid flags")
+	}
+
+	items, err := readBatchInput(args[0])
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	// Skip IDs already recorded by an earlier run
+	pending := items
+	if batchOut != "" {
+		done, err := batch.ReadDone(batchOut, batchRetryFailed)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		pending = pending[:0:0]
+		for _, item := range items {
+			if !done[item.ID] {
+				pending = append(pending, item)
+			}
+		}
+	}
+	skipped := len(items) - len(pending)
+	if len(pending) == 0 {
+		fmt.Fprintf(os.Stderr, "✓ Nothing to do: all %d item(s) are already in %s\n", len(items), batchOut)
+		return nil
+	}
+
+	out := io.Writer(os.Stdout)
+	if batchOut != "" {
+		f, err := batch.OpenOutput(batchOut)
+		if err != nil {
+			PrintError(fmt.Sprintf("failed to open output file: %v", err))
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+
+	client, err := newChatClient(cfg)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	runner := &batch.Runner{
+		Client:      client,
+		Concurrency: batchConcurrency,
+		Retries:     batchRetries,
+		Backoff:     time.Second,
+		Request: func(item batch.Item) (*api.ChatCompletionRequest, error) {
+			return batchRequest(cmd, cfg, item)
+		},
+		Cost: catalogPricer(client),
+	}
+
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "Skipping %d item(s) already in %s\n", skipped, batchOut)
+	}
+	var succeeded, failed int
+	var cost float64
+	err = runner.Run(pending, func(r batch.Result) error {
+		status := "ok"
+		if r.Error != "" {
+			failed++
+			status = "failed: " + r.Error
+		} else {
+			succeeded++
+		}
+		if r.Cost != nil {
+			cost += *r.Cost
+		}
+		fmt.Fprintf(os.Stderr, "[%d/%d] %s %s (%.1fs)\n", succeeded+failed, len(pending), r.ID, status, float64(r.DurationMS)/1000)
+
+		line, err := json.Marshal(r)
+		if err != nil {
+			return err
+		}
+		_, err = out.Write(append(line, '\n'))
+		return err
+	})
+	if err != nil {
+		PrintError(fmt.Sprintf("failed to write results: %v", err))
+		return err
+	}
+
+	summary := fmt.Sprintf("%d succeeded, %d failed", succeeded, failed)
+	if skipped > 0 {
+		summary += fmt.Sprintf(", %d skipped", skipped)
+	}
+	summary += fmt.Sprintf("; cost $%.4f", cost)
+	if failed > 0 {
+		err := fmt.Errorf("%d item(s) failed", failed)
+		PrintError(summary)
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "✓ %s\n", summary)
+	return nil
+}
+
+// readBatchInput reads and checks the items in path, or stdin for "-"
+func readBatchInput(path string) ([]batch.Item, error) {
+	if path == "-" {
+		return batch.ReadItems(os.Stdin)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open input: %w", err)
+	}
+	defer f.Close()
+	return batch.ReadItems(f)
+}
+
+// batchRequest builds the request for an item, filling in values it leaves
+// out from the command's flags and then the config
+func batchRequest(cmd *cobra.Command, cfg *config.Config, item batch.Item) (*api.ChatCompletionRequest, error) {
+	modelName := item.Model
+	if modelName == "" {
+		modelName = batchModel
+	}
+	if modelName == "" {
+		modelName = cfg.DefaultModel
+	}
+	modelID, err := cfg.ResolveModel(modelName)
+	if err != nil {
+		return nil, err
+	}
+
+	temp := cfg.DefaultTemp
+	if cmd.Flags().Changed("temperature") {
+		temp = batchTemperature
+	}
+	if item.Temperature != nil {
+		temp = *item.Temperature
+	}
+	maxTok := cfg.DefaultMaxTokens
+	if cmd.Flags().Changed("max-tokens") {
+		maxTok = batchMaxTokens
+	}
+	if item.MaxTokens != nil {
+		maxTok = *item.MaxTokens
+	}
+
+	return &api.ChatCompletionRequest{
+		Model:       modelID,
+		Messages:    item.ChatMessages(),
+		Temperature: &temp,
+		MaxTokens:   maxTok,
+		Provider:    providerPreferences(cfg.Routing),
+	}, nil
+}
+
+// catalogPricer prices responses from the model catalog, fetched once
+// Costs are left out if the catalog can't be fetched.
+func catalogPricer(client *api.Client) func(string, api.Usage) (float64, bool) {
+	models, err := client.ListModels()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: costs will be left out: failed to fetch model prices: %v\n", err)
+		return nil
+	}
+	pricing := make(map[string]api.ModelPricing, len(models))
+	for _, m := range models {
+		pricing[m.ID] = m.Pricing
+	}
+	return func(model string, usage api.Usage) (float64, bool) {
+		p, ok := pricing[model]
+		if !ok {
+			return 0, false
+		}
+		return p.Cost(usage)
+	}
+}
+
+func init() {
+	batchCmd.Flags().StringVarP(&batchModel, "model", "m", "", "Model ID or alias for lines without a model")
+	batchCmd.RegisterFlagCompletionFunc("model", completeAliases)
+	batchCmd.Flags().Float64VarP(&batchTemperature, "temperature", "t", 0, "Temperature for lines without one (0.0-2.0)")
+	batchCmd.Flags().IntVar(&batchMaxTokens, "max-tokens", 0, "Maximum tokens for lines without max_tokens")
+	batchCmd.Flags().IntVarP(&batchConcurrency, "concurrency", "j", 4, "Number of requests to send at once")
+	batchCmd.Flags().IntVar(&batchRetries, "retries", 3, "Retries for rate-limited, timed-out or failed requests")
+	batchCmd.Flags().StringVar(&batchOut, "out", "", "Append results to this file and skip IDs already in it (default: stdout)")
+	batchCmd.Flags().BoolVar(&batchRetryFailed, "retry-failed", false, "Re-run IDs whose result in --out is an error")
+}
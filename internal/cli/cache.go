@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/cache"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Cache command flags
+	clearExpired bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Inspect or clear the response cache",
+	Long: `Inspect or clear the on-disk cache of chat responses.
+
+The cache is off unless the cache key is set to true (or --cache-only is
+given). When it is on, a chat request identical to an earlier one (same
+model, messages, temperature, max tokens and routing) is answered from the
+cache until cache_ttl passes. Once the cache grows past cache_max_size the
+least recently used responses are removed.
+
+Examples:
+  openrouter config set cache true
+  openrouter cache stats
+  openrouter cache clear --expired
+  openrouter chat --no-cache "Tell me a joke"`,
+}
+
+var cacheStatsCmd = &cobra.Command{
+	Use:   "stats",
+	Short: "Show the number and size of cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, c, err := loadResponseCache()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		stats, err := c.Stats()
+		if err != nil {
+			PrintError(fmt.Sprintf("failed to read cache: %v", err))
+			return err
+		}
+
+		format, err := ResolveOutputFormat(cfg, "")
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		switch format {
+		case FormatJSON:
+			data, err := json.MarshalIndent(stats, "", "  ")
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+			return nil
+		case FormatYAML:
+			return writeYAML(os.Stdout, stats)
+		case FormatNDJSON:
+			return writeNDJSON(os.Stdout, []*cache.Stats{stats})
+		}
+
+		enabled := "no (set cache to true to enable)"
+		if cfg.CacheEnabled() {
+			enabled = "yes"
+		}
+		fmt.Printf("Enabled:    %s\n", enabled)
+		fmt.Printf("Directory:  %s\n", stats.Dir)
+		fmt.Printf("Responses:  %d (%d expired)\n", stats.Entries, stats.Expired)
+		fmt.Printf("Size:       %s of %s\n", util.FormatSize(stats.Size), util.FormatSize(stats.MaxSize))
+		fmt.Printf("TTL:        %s\n", stats.TTL)
+		if stats.Entries > 0 {
+			fmt.Printf("Oldest:     %s\n", stats.Oldest.Local().Format(time.DateTime))
+			fmt.Printf("Newest:     %s\n", stats.Newest.Local().Format(time.DateTime))
+		}
+		return nil
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove cached responses",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		_, c, err := loadResponseCache()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		removed, err := c.Clear(clearExpired)
+		if err != nil {
+			PrintError(fmt.Sprintf("failed to clear cache: %v", err))
+			return err
+		}
+		kind := "cached"
+		if clearExpired {
+			kind = "expired"
+		}
+		fmt.Printf("✓ Removed %d %s response(s)\n", removed, kind)
+		return nil
+	},
+}
+
+// loadResponseCache opens the configured cache without needing an API key
+func loadResponseCache() (*config.Config, *cache.Cache, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	printConfigWarnings(cfg)
+	ttl, maxSize, err := cfg.CacheLimits()
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, cache.New(cfg.ResponseCacheDir(), ttl, maxSize), nil
+}
+
+// newChatClient creates a client for chat requests, using the response
+// cache when it is enabled and not turned off with --no-cache
+func newChatClient(cfg *config.Config) (*api.Client, error) {
+	if noCache && cacheOnly {
+		return nil, fmt.Errorf("--no-cache and --cache-only can't be used together")
+	}
+	client := api.NewClient(cfg.APIBaseURL, cfg.APIKey, cfg.Timeout)
+	if noCache || !(cfg.CacheEnabled() || cacheOnly) {
+		return client, nil
+	}
+	ttl, maxSize, err := cfg.CacheLimits()
+	if err != nil {
+		return nil, err
+	}
+	client.Cache = cache.ForBaseURL(cfg.ResponseCacheDir(), ttl, maxSize, cfg.APIBaseURL)
+	client.CacheOnly = cacheOnly
+	return client, nil
+}
+
+func init() {
+	cacheClearCmd.Flags().BoolVar(&clearExpired, "expired", false, "Only remove responses older than cache_ttl")
+	cacheCmd.AddCommand(cacheStatsCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 
 	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/templates"
 	"github.com/kdevrou/openrouter-cli/internal/util"
 	"github.com/spf13/cobra"
 )
@@ -17,6 +19,15 @@ var (
 	rawOutput   bool
 	jsonOutput  bool
 	useStdin    bool
+	noRender    bool
+	extractCode bool
+	codeLang    string
+	saveCodeDir string
+	forceSave   bool
+	inputFiles  []string
+	maxFileSize string
+	promptTmpl  string
+	tmplVars    []string
 )
 
 var chatCmd = &cobra.Command{
@@ -28,14 +39,40 @@ Input options:
   openrouter chat "What is Go?"                    # Argument only
   echo "Explain quantum computing" | openrouter chat  # Pipe only
   cat file.txt | openrouter chat --stdin "Analyze:"   # Combine both
+  openrouter chat -f main.go -f 'pkg/**/*.go' "Review"  # Attach files
+  openrouter chat --template review --var lang=go < diff.patch  # Use a template
 
 Flags let you customize the request:
   -m, --model: Choose which model to use
   -t, --temperature: Adjust response creativity (0.0-2.0)
   --max-tokens: Limit response length
   --stdin: Combine argument with piped input
+  -f, --file: Attach a file, directory or glob (repeatable)
+  --template: Render a prompt template (see 'openrouter templates')
+  --var: Set a template variable, name=value (repeatable)
   --raw: Output only the response text (for piping)
-  --json: Output full API response as JSON`,
+  --json: Output full API response as JSON
+  --no-render: Show markdown as plain text (it is only rendered on a terminal)
+  --extract-code: Print only the fenced code blocks from the reply
+  --save-code: Write each code block to a file in a directory
+  -o, --output: Output format (pretty, raw, json, csv, tsv, yaml, ndjson)
+  --format: Go template evaluated against the API response, e.g.
+            --format '{{.Model}} {{(index .Choices 0).Message.Content}}'
+
+Code extraction:
+  openrouter chat --extract-code --lang go "Write a Go HTTP server" > server.go
+  openrouter chat --save-code ./out "Write a Python CLI with tests"
+
+Saved files are named from the fence info string (` + "```go main.go" + `) or a
+leading "// file: path" comment, falling back to snippet-N.<ext>.
+Existing files are never overwritten unless --force is given.
+
+File attachments:
+Each file is added after the prompt in a fenced block labeled with its path
+and language. Globs support ** for any number of directories; quote them so
+the shell doesn't expand them. Directories and globs skip files excluded by
+.gitignore, and binary files and files over --max-file-size are skipped. The
+estimated token count is printed to stderr before the request is sent.`,
 
 	Args: cobra.MaximumNArgs(1),
 	RunE: runChat,
@@ -44,54 +81,126 @@ Flags let you customize the request:
 func runChat(cmd *cobra.Command, args []string) error {
 	// Load configuration
 	cfg, err := GetConfig()
-	if err != nil {
+	if err == config.ErrNoAPIKey {
 		PrintSetupError()
+	} else if err != nil {
+		PrintError(err.Error())
+		return err
 	}
 
-	// Get input from args or stdin
-	prompt, err := util.CombineInputWithStdin(args, useStdin)
+	// Resolve output format before sending anything
+	var shortcut OutputFormat
+	if jsonOutput {
+		shortcut = FormatJSON
+	} else if rawOutput {
+		shortcut = FormatRaw
+	}
+	format, err := ResolveOutputFormat(cfg, shortcut)
 	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	tmpl, err := parseFormatFlag(shortcut, api.ChatCompletionResponse{})
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	if codeLang != "" && !extractCode && saveCodeDir == "" {
+		PrintError("--lang requires --extract-code or --save-code")
+		return fmt.Errorf("invalid flags")
+	}
+
+	// Get input from args or stdin; a template or attached files can stand
+	// in for both. Templates read piped input without --stdin.
+	prompt, err := util.CombineInputWithStdin(args, useStdin || promptTmpl != "")
+	if err != nil && len(inputFiles) == 0 && promptTmpl == "" {
 		PrintError(err.Error())
 		return fmt.Errorf("no input provided")
 	}
 
+	// Render the template before anything is sent so missing variables are caught
+	var system string
+	var tmplMeta templates.Meta
+	if promptTmpl != "" {
+		var t *templates.Template
+		prompt, system, t, err = loadPromptTemplate(cfg, promptTmpl, tmplVars, prompt)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		tmplMeta = t.Meta
+	} else if len(tmplVars) > 0 {
+		PrintError("--var requires --template")
+		return fmt.Errorf("invalid flags")
+	}
+	if len(inputFiles) > 0 {
+		maxSize, err := util.ParseSize(maxFileSize)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		prompt, err = attachFiles(prompt, inputFiles, maxSize)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+	}
+
 	if prompt == "" {
 		PrintError("prompt cannot be empty")
 		return fmt.Errorf("empty prompt")
 	}
 
-	// Use provided model or default
+	// Use provided model, then the template's, then the default, expanding aliases
 	selectedModel := model
+	if selectedModel == "" {
+		selectedModel = tmplMeta.Model
+	}
 	if selectedModel == "" {
 		selectedModel = cfg.DefaultModel
 	}
+	selectedModel, err = resolveModel(cfg, selectedModel)
+	if err != nil {
+		return err
+	}
 
 	// Use provided temperature or default
 	selectedTemp := temperature
 	if selectedTemp == 0 && !cmd.Flags().Changed("temperature") {
 		selectedTemp = cfg.DefaultTemp
+		if tmplMeta.Temperature != nil {
+			selectedTemp = *tmplMeta.Temperature
+		}
 	}
 
 	// Use provided maxTokens or default
 	selectedMaxTokens := maxTokens
 	if selectedMaxTokens == 0 && !cmd.Flags().Changed("max-tokens") {
 		selectedMaxTokens = cfg.DefaultMaxTokens
+		if tmplMeta.MaxTokens != nil {
+			selectedMaxTokens = *tmplMeta.MaxTokens
+		}
 	}
 
 	// Create API client
-	apiClient := api.NewClient(cfg.APIBaseURL, cfg.APIKey, cfg.Timeout)
+	apiClient, err := newChatClient(cfg)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
 
 	// Build request
+	var messages []api.Message
+	if system != "" {
+		messages = append(messages, api.Message{Role: "system", Content: system})
+	}
+	messages = append(messages, api.Message{Role: "user", Content: prompt})
 	chatReq := &api.ChatCompletionRequest{
-		Model: selectedModel,
-		Messages: []api.Message{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Temperature: selectedTemp,
+		Model:       selectedModel,
+		Messages:    messages,
+		Temperature: &selectedTemp,
 		MaxTokens:   selectedMaxTokens,
+		Provider:    providerPreferences(cfg.Routing),
 	}
 
 	// Send request
@@ -103,28 +212,82 @@ func runChat(cmd *cobra.Command, args []string) error {
 	if err != nil {
 		if apiErr, ok := err.(*api.APIError); ok {
 			PrintAPIError(apiErr)
+			if apiErr.IsProviderUnavailable() {
+				suggestUnavailable(selectedModel, apiErr)
+			}
 		} else {
 			PrintError(err.Error())
 		}
 		return err
 	}
 
-	// Format output
-	format := FormatPretty
-	if jsonOutput {
-		format = FormatJSON
-	} else if rawOutput {
-		format = FormatRaw
+	// Extract code blocks instead of printing the full reply
+	if extractCode || saveCodeDir != "" {
+		if len(resp.Choices) == 0 {
+			return fmt.Errorf("no choices in response - model may be unavailable or rate-limited")
+		}
+		blocks := selectCodeBlocks(resp.Choices[0].Message.Content, codeLang)
+		if len(blocks) == 0 {
+			PrintError("no code blocks found in response")
+			return fmt.Errorf("no code blocks")
+		}
+		if saveCodeDir != "" {
+			paths, err := saveCodeBlocks(blocks, saveCodeDir, forceSave)
+			if err != nil {
+				PrintError(err.Error())
+				return err
+			}
+			for _, p := range paths {
+				fmt.Fprintf(os.Stderr, "✓ Wrote %s\n", p)
+			}
+		}
+		if extractCode {
+			printCodeBlocks(blocks)
+		}
+		return nil
 	}
 
+	// Format output
+	if tmpl != nil {
+		if len(resp.Choices) == 0 {
+			return fmt.Errorf("no choices in response - model may be unavailable or rate-limited")
+		}
+		return executeTemplate(os.Stdout, tmpl, resp)
+	}
 	return FormatChatResponse(resp, format)
 }
 
+// providerPreferences converts configured routing preferences into the request field
+func providerPreferences(routing *config.Routing) *api.ProviderPreferences {
+	if routing == nil {
+		return nil
+	}
+	return &api.ProviderPreferences{
+		Order:          routing.Order,
+		AllowFallbacks: routing.AllowFallbacks,
+		Only:           routing.Only,
+		Ignore:         routing.Ignore,
+		DataCollection: routing.DataCollection,
+		Sort:           routing.Sort,
+	}
+}
+
 func init() {
-	chatCmd.Flags().StringVarP(&model, "model", "m", "", "Model to use (e.g., openai/gpt-4)")
+	chatCmd.Flags().StringVarP(&model, "model", "m", "", "Model ID or alias to use (e.g., openai/gpt-4)")
+	chatCmd.RegisterFlagCompletionFunc("model", completeAliases)
 	chatCmd.Flags().Float64VarP(&temperature, "temperature", "t", 0, "Temperature for response generation (0.0-2.0)")
 	chatCmd.Flags().IntVar(&maxTokens, "max-tokens", 0, "Maximum tokens in response")
 	chatCmd.Flags().BoolVar(&useStdin, "stdin", false, "Combine argument with piped input (cat file.txt | openrouter chat --stdin 'Analyze:')")
+	chatCmd.Flags().StringArrayVarP(&inputFiles, "file", "f", nil, "Attach a file, directory or glob such as 'pkg/**/*.go' (repeatable)")
+	chatCmd.Flags().StringVar(&promptTmpl, "template", "", "Prompt template to render (see 'openrouter templates list')")
+	chatCmd.RegisterFlagCompletionFunc("template", completeTemplates)
+	chatCmd.Flags().StringArrayVar(&tmplVars, "var", nil, "Set a template variable as name=value (repeatable)")
+	chatCmd.Flags().StringVar(&maxFileSize, "max-file-size", util.FormatSize(util.DefaultMaxFileSize), "Skip attached files larger than this (e.g. 512k, 2M; 0 for no limit)")
 	chatCmd.Flags().BoolVar(&rawOutput, "raw", false, "Output only the response text (no formatting)")
 	chatCmd.Flags().BoolVar(&jsonOutput, "json", false, "Output full API response as JSON")
+	chatCmd.Flags().BoolVar(&noRender, "no-render", false, "Print markdown as plain text instead of rendering it")
+	chatCmd.Flags().BoolVar(&extractCode, "extract-code", false, "Output only the fenced code blocks from the response")
+	chatCmd.Flags().StringVar(&codeLang, "lang", "", "Only extract code blocks in this language (e.g., go)")
+	chatCmd.Flags().StringVar(&saveCodeDir, "save-code", "", "Write each code block to a file in this directory")
+	chatCmd.Flags().BoolVar(&forceSave, "force", false, "Overwrite existing files with --save-code")
 }
@@ -0,0 +1,162 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// modelColumn describes a column that can be shown in the model table
+type modelColumn struct {
+	Header string
+	// Flexible columns are truncated first when the table is wider than the terminal
+	Flexible bool
+	// Value formats the cell for the table, Raw for CSV/TSV output
+	Value func(m api.Model) string
+	Raw   func(m api.Model) string
+}
+
+// modelColumns holds every column supported by 'openrouter list --columns'
+var modelColumns = map[string]modelColumn{
+	"id": {
+		Header:   "Model ID",
+		Flexible: true,
+		Value:    func(m api.Model) string { return m.ID },
+		Raw:      func(m api.Model) string { return m.ID },
+	},
+	"name": {
+		Header:   "Name",
+		Flexible: true,
+		Value:    func(m api.Model) string { return m.Name },
+		Raw:      func(m api.Model) string { return m.Name },
+	},
+	"context": {
+		Header: "Context",
+		Value:  func(m api.Model) string { return formatContext(m.ContextLength) },
+		Raw:    func(m api.Model) string { return strconv.Itoa(m.ContextLength) },
+	},
+	"prompt_price": {
+		Header: "Prompt $/1M",
+		Value:  func(m api.Model) string { return formatPricePerMillion(m.Pricing.Prompt) },
+		Raw:    func(m api.Model) string { return m.Pricing.Prompt },
+	},
+	"completion_price": {
+		Header: "Completion $/1M",
+		Value:  func(m api.Model) string { return formatPricePerMillion(m.Pricing.Completion) },
+		Raw:    func(m api.Model) string { return m.Pricing.Completion },
+	},
+	"modality": {
+		Header: "Modality",
+		Value: func(m api.Model) string {
+			if m.Architecture.Modality == "" {
+				return "text"
+			}
+			return m.Architecture.Modality
+		},
+		Raw: func(m api.Model) string { return m.Architecture.Modality },
+	},
+	"created": {
+		Header: "Created",
+		Value: func(m api.Model) string {
+			if m.Created == 0 {
+				return "-"
+			}
+			return time.Unix(m.Created, 0).UTC().Format("2006-01-02")
+		},
+		Raw: func(m api.Model) string {
+			if m.Created == 0 {
+				return ""
+			}
+			return time.Unix(m.Created, 0).UTC().Format(time.RFC3339)
+		},
+	},
+	"tokenizer": {
+		Header: "Tokenizer",
+		Value: func(m api.Model) string {
+			if m.Architecture.Tokenizer == "" {
+				return "-"
+			}
+			return m.Architecture.Tokenizer
+		},
+		Raw: func(m api.Model) string { return m.Architecture.Tokenizer },
+	},
+}
+
+// ModelColumnNames lists all supported model table columns in display order
+var ModelColumnNames = []string{
+	"id", "name", "context", "prompt_price", "completion_price", "modality", "created", "tokenizer",
+}
+
+// DefaultModelColumns are shown when --columns is not given
+var DefaultModelColumns = []string{"id", "context", "prompt_price", "completion_price", "modality"}
+
+// ParseModelColumns validates a list of column names, accepting comma-separated entries
+func ParseModelColumns(names []string) ([]string, error) {
+	var columns []string
+	for _, name := range names {
+		for _, col := range strings.Split(name, ",") {
+			col = strings.ToLower(strings.TrimSpace(col))
+			if col == "" {
+				continue
+			}
+			if _, ok := modelColumns[col]; !ok {
+				return nil, fmt.Errorf("unknown column %q (valid columns: %s)",
+					col, strings.Join(ModelColumnNames, ", "))
+			}
+			columns = append(columns, col)
+		}
+	}
+
+	if len(columns) == 0 {
+		return DefaultModelColumns, nil
+	}
+	return columns, nil
+}
+
+// formatContext formats a context length compactly, e.g. 131072 as 128K
+func formatContext(length int) string {
+	switch {
+	case length <= 0:
+		return "-"
+	case length%1000000 == 0:
+		return fmt.Sprintf("%dM", length/1000000)
+	case length%(1024*1024) == 0:
+		return fmt.Sprintf("%dM", length/(1024*1024))
+	case length%1000 == 0:
+		return fmt.Sprintf("%dK", length/1000)
+	case length%1024 == 0:
+		return fmt.Sprintf("%dK", length/1024)
+	case length >= 1000:
+		return fmt.Sprintf("%dK", (length+500)/1000)
+	default:
+		return strconv.Itoa(length)
+	}
+}
+
+// formatPricePerMillion converts a per-token USD price string into dollars per 1M tokens
+func formatPricePerMillion(price string) string {
+	if price == "" {
+		return "free"
+	}
+
+	perToken, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return price
+	}
+
+	perMillion := perToken * 1e6
+	switch {
+	case perMillion < 0:
+		// OpenRouter reports -1 for routers whose price depends on the chosen model
+		return "varies"
+	case perMillion == 0:
+		return "free"
+	case perMillion < 0.01:
+		return fmt.Sprintf("$%.4f", perMillion)
+	default:
+		return fmt.Sprintf("$%.2f", perMillion)
+	}
+}
@@ -0,0 +1,303 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/batch"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Compare command flags
+	compareModels      []string
+	compareTemperature float64
+	compareMaxTokens   int
+	compareStdin       bool
+	compareJSON        bool
+	compareStacked     bool
+)
+
+// minCompareColumn is the narrowest answer column shown side by side;
+// narrower terminals get the answers stacked
+const minCompareColumn = 30
+
+// compareHeaders are the columns written for compare results in CSV and TSV
+var compareHeaders = []string{
+	"id", "model", "latency_ms", "prompt_tokens", "completion_tokens", "total_tokens", "cost", "error", "response",
+}
+
+var compareCmd = &cobra.Command{
+	Use:   "compare -m <model> -m <model> [prompt]",
+	Short: "Send one prompt to several models and compare the answers",
+	Long: `Send the same prompt to several models at once and show the answers
+side by side, with the latency, tokens and cost of each.
+
+Answers are shown in columns when the terminal is wide enough and stacked
+otherwise (or with --stacked). With --json, or -o json/ndjson/yaml/csv/tsv,
+one record is written per model, in the order the models were given; id is
+the model as given with -m and model is the one that answered.
+
+Examples:
+  openrouter compare -m openai/gpt-4o -m anthropic/claude-3.5-sonnet "Explain monads"
+  openrouter compare -m fast -m sonnet -t 0 "Write a regex for ISO dates"
+  git diff | openrouter compare -m a -m b --stdin "Review this diff"
+  openrouter compare -m a -m b --json "Hello" | jq '.[] | {id, cost}'`,
+	Args: cobra.MaximumNArgs(1),
+	RunE: runCompare,
+}
+
+func runCompare(cmd *cobra.Command, args []string) error {
+	cfg, err := GetConfig()
+	if err == config.ErrNoAPIKey {
+		PrintSetupError()
+		return err
+	} else if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	var shortcut OutputFormat
+	if compareJSON {
+		shortcut = FormatJSON
+	}
+	format, err := ResolveOutputFormat(cfg, shortcut)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	if len(compareModels) < 2 {
+		PrintError("give at least two models with -m")
+		return fmt.Errorf("invalid flags")
+	}
+	seen := make(map[string]bool)
+	for _, m := range compareModels {
+		if seen[m] {
+			PrintError(fmt.Sprintf("model %s is given more than once", m))
+			return fmt.Errorf("invalid flags")
+		}
+		seen[m] = true
+		if _, err := resolveModel(cfg, m); err != nil {
+			return err
+		}
+	}
+
+	prompt, err := util.CombineInputWithStdin(args, compareStdin)
+	if err != nil {
+		PrintError(err.Error())
+		return fmt.Errorf("no input provided")
+	}
+	if prompt == "" {
+		PrintError("prompt cannot be empty")
+		return fmt.Errorf("empty prompt")
+	}
+
+	// Each model is one item, so all of them are sent at once
+	items := make([]batch.Item, len(compareModels))
+	for i, m := range compareModels {
+		items[i] = batch.Item{ID: m, Model: m, Prompt: prompt}
+	}
+	client, err := newChatClient(cfg)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	runner := &batch.Runner{
+		Client:      client,
+		Concurrency: len(items),
+		Request: func(item batch.Item) (*api.ChatCompletionRequest, error) {
+			return compareRequest(cmd, cfg, item)
+		},
+		Cost: catalogPricer(client),
+	}
+
+	byID := make(map[string]batch.Result)
+	runner.Run(items, func(r batch.Result) error {
+		byID[r.ID] = r
+		return nil
+	})
+	results := make([]batch.Result, len(items))
+	failed := 0
+	for i, item := range items {
+		results[i] = byID[item.ID]
+		if results[i].Error != "" {
+			failed++
+		}
+	}
+
+	if err := formatCompare(results, format); err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	if failed == len(results) {
+		return fmt.Errorf("every model failed")
+	}
+	return nil
+}
+
+// compareRequest builds the request for one model, using the config
+// defaults for values not given as flags
+func compareRequest(cmd *cobra.Command, cfg *config.Config, item batch.Item) (*api.ChatCompletionRequest, error) {
+	modelID, err := cfg.ResolveModel(item.Model)
+	if err != nil {
+		return nil, err
+	}
+	temp := cfg.DefaultTemp
+	if cmd.Flags().Changed("temperature") {
+		temp = compareTemperature
+	}
+	maxTok := cfg.DefaultMaxTokens
+	if cmd.Flags().Changed("max-tokens") {
+		maxTok = compareMaxTokens
+	}
+	return &api.ChatCompletionRequest{
+		Model:       modelID,
+		Messages:    item.ChatMessages(),
+		Temperature: &temp,
+		MaxTokens:   maxTok,
+		Provider:    providerPreferences(cfg.Routing),
+	}, nil
+}
+
+// formatCompare writes compare results in the given format
+func formatCompare(results []batch.Result, format OutputFormat) error {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(results, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal results: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	case FormatNDJSON:
+		return writeNDJSON(os.Stdout, results)
+	case FormatYAML:
+		return writeYAML(os.Stdout, results)
+	case FormatCSV, FormatTSV:
+		rows := make([][]string, len(results))
+		for i, r := range results {
+			var usage api.Usage
+			if r.Usage != nil {
+				usage = *r.Usage
+			}
+			cost := ""
+			if r.Cost != nil {
+				cost = strconv.FormatFloat(*r.Cost, 'f', -1, 64)
+			}
+			rows[i] = []string{
+				r.ID, r.Model, strconv.FormatInt(r.DurationMS, 10),
+				strconv.Itoa(usage.PromptTokens), strconv.Itoa(usage.CompletionTokens), strconv.Itoa(usage.TotalTokens),
+				cost, r.Error, r.Response,
+			}
+		}
+		return writeDelimited(os.Stdout, compareHeaders, rows, delimiter(format))
+	}
+
+	width := util.TerminalWidth()
+	column := (width - 3*(len(results)-1)) / len(results)
+	if compareStacked || format == FormatRaw || column < minCompareColumn {
+		printStacked(results)
+	} else {
+		printSideBySide(results, column)
+	}
+	return nil
+}
+
+// describeCompareStats summarizes a result's latency, tokens and cost
+func describeCompareStats(r batch.Result) string {
+	parts := []string{fmt.Sprintf("%.1fs", float64(r.DurationMS)/1000)}
+	if r.Usage != nil {
+		parts = append(parts, fmt.Sprintf("%d tokens", r.Usage.TotalTokens))
+	}
+	if r.Cost != nil {
+		parts = append(parts, fmt.Sprintf("$%.4f", *r.Cost))
+	}
+	if r.Cached {
+		parts = append(parts, "cached")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// compareAnswer returns a result's response, or its error
+func compareAnswer(r batch.Result) string {
+	if r.Error != "" {
+		return "Error: " + r.Error
+	}
+	return strings.TrimSpace(r.Response)
+}
+
+// printStacked prints each answer under a header naming the model
+func printStacked(results []batch.Result) {
+	for i, r := range results {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Println(color.CyanString("== %s (%s) ==", r.ID, describeCompareStats(r)))
+		if r.Error != "" {
+			fmt.Println(color.RedString(compareAnswer(r)))
+			continue
+		}
+		fmt.Println(compareAnswer(r))
+	}
+}
+
+// printSideBySide prints the answers in columns of the given width
+func printSideBySide(results []batch.Result, width int) {
+	separator := " | "
+	columns := make([][]string, len(results))
+	height := 0
+	for i, r := range results {
+		lines := strings.Split(WordWrap(compareAnswer(r), width), "\n")
+		for j, line := range lines {
+			lines[j] = truncate(strings.ReplaceAll(line, "\t", "    "), width)
+		}
+		columns[i] = lines
+		height = max(height, len(lines))
+	}
+
+	row := func(cells []string) {
+		padded := make([]string, len(cells))
+		for i, cell := range cells {
+			padded[i] = cell + strings.Repeat(" ", max(0, width-utf8.RuneCountInString(cell)))
+		}
+		fmt.Println(strings.TrimRight(strings.Join(padded, separator), " "))
+	}
+
+	headers := make([]string, len(results))
+	stats := make([]string, len(results))
+	for i, r := range results {
+		headers[i] = truncate(r.ID, width)
+		stats[i] = truncate(describeCompareStats(r), width)
+	}
+	row(headers)
+	row(stats)
+	fmt.Println(strings.Repeat("-", width*len(results)+len(separator)*(len(results)-1)))
+	for line := 0; line < height; line++ {
+		cells := make([]string, len(columns))
+		for i, lines := range columns {
+			if line < len(lines) {
+				cells[i] = lines[line]
+			}
+		}
+		row(cells)
+	}
+}
+
+func init() {
+	compareCmd.Flags().StringArrayVarP(&compareModels, "model", "m", nil, "Model ID or alias to compare (repeat for each model)")
+	compareCmd.RegisterFlagCompletionFunc("model", completeAliases)
+	compareCmd.Flags().Float64VarP(&compareTemperature, "temperature", "t", 0, "Temperature for every model (0.0-2.0)")
+	compareCmd.Flags().IntVar(&compareMaxTokens, "max-tokens", 0, "Maximum tokens in each response")
+	compareCmd.Flags().BoolVar(&compareStdin, "stdin", false, "Combine argument with piped input")
+	compareCmd.Flags().BoolVar(&compareJSON, "json", false, "Output one JSON record per model")
+	compareCmd.Flags().BoolVar(&compareStacked, "stacked", false, "Show answers one after another instead of in columns")
+}
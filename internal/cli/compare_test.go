@@ -0,0 +1,50 @@
+package cli
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/kdevrou/openrouter-cli/internal/batch"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// compareFlags returns a command with compare's request flags parsed from args
+func compareFlags(t *testing.T, args ...string) *cobra.Command {
+	t.Helper()
+	cmd := &cobra.Command{}
+	cmd.Flags().Float64VarP(&compareTemperature, "temperature", "t", 0, "")
+	cmd.Flags().IntVar(&compareMaxTokens, "max-tokens", 0, "")
+	if err := cmd.Flags().Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cmd
+}
+
+func TestCompareRequestTemperature(t *testing.T) {
+	cfg := &config.Config{DefaultTemp: 0.7, DefaultMaxTokens: 500}
+	item := batch.Item{Model: "openai/gpt-4", Prompt: "hi"}
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{nil, `"temperature":0.7`},
+		{[]string{"-t", "0"}, `"temperature":0,`},
+		{[]string{"-t", "1.2"}, `"temperature":1.2`},
+	}
+	for _, tt := range tests {
+		req, err := compareRequest(compareFlags(t, tt.args...), cfg, item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := json.Marshal(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(string(data), tt.want) {
+			t.Errorf("args %v: request %s doesn't contain %s", tt.args, data, tt.want)
+		}
+	}
+}
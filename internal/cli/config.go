@@ -2,31 +2,71 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"strings"
 
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/api"
 	"github.com/kdevrou/openrouter-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
+var (
+	// showOrigin is the 'config show --origin' flag
+	showOrigin bool
+
+	// Unavailable-model flags
+	unavailableFor    string
+	unavailableReason string
+	pruneDryRun       bool
+)
+
 var configCmd = &cobra.Command{
 	Use:   "config",
 	Short: "Manage configuration settings",
 	Long: `Manage OpenRouter CLI configuration.
 
+Configuration is layered (later layers win):
+  1. Built-in defaults
+  2. User config file (--config, $OPENROUTER_CONFIG, or the default location)
+  3. Project-local .openrouter.yaml files, from the outermost directory inwards
+  4. The selected profile (--profile, $OPENROUTER_PROFILE or current_profile)
+  5. Environment variables (OPENROUTER_API_KEY, OPENROUTER_DEFAULT_MODEL, ...)
+  6. Command-line flags (--api-key)
+
+'config set' and the unavailable-model commands always write the user config file.
+Use 'openrouter config show --origin' to see where each value came from.
+
 Examples:
   openrouter config get api_key
   openrouter config set default_model openai/gpt-4
+  openrouter config set routing.sort price
+  openrouter config unset routing.sort
+  openrouter config list-keys
+  openrouter config doctor
+  openrouter config migrate
+  openrouter config edit
   openrouter config add-unavailable qwen/model:free
   openrouter config remove-unavailable qwen/model:free
-  openrouter config list-unavailable`,
+  openrouter config add-unavailable 'qwen/*:free' --for 7d
+  openrouter config list-unavailable
+  openrouter config prune-unavailable
+  openrouter config profile list`,
 }
 
 var getCmd = &cobra.Command{
-	Use:   "get <key>",
-	Short: "Get a configuration value",
-	Args:  cobra.ExactArgs(1),
+	Use:               "get <key>",
+	Short:             "Get a configuration value",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKeys,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		field, err := config.LookupField(args[0])
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		cfg, err := loadConfig()
 		if err != nil && err != config.ErrNoAPIKey {
 			PrintError(err.Error())
 			return err
@@ -34,40 +74,27 @@ var getCmd = &cobra.Command{
 		if cfg == nil {
 			cfg = config.DefaultConfig()
 		}
+		printConfigWarnings(cfg)
 
-		key := args[0]
-		switch key {
-		case "api_key":
-			if cfg.APIKey == "" {
-				fmt.Println("(not set)")
-			} else {
-				// Show only last 4 characters for security
-				visible := "sk-..." + cfg.APIKey[len(cfg.APIKey)-4:]
-				fmt.Println(visible)
-			}
-		case "default_model":
-			fmt.Println(cfg.DefaultModel)
-		case "default_temperature":
-			fmt.Println(cfg.DefaultTemp)
-		case "default_max_tokens":
-			fmt.Println(cfg.DefaultMaxTokens)
-		case "output_format":
-			fmt.Println(cfg.OutputFormat)
-		case "api_base_url":
-			fmt.Println(cfg.APIBaseURL)
-		case "timeout":
-			fmt.Println(cfg.Timeout)
-		case "unavailable_models":
-			if len(cfg.UnavailableModels) == 0 {
+		switch {
+		case field.Key == "api_key":
+			// Never print the full key
+			display, _ := describeAPIKey(cfg)
+			fmt.Println(display)
+		case field.Secret:
+			fmt.Println(maskSecret(field.Get(cfg)))
+		case field.Type == config.TypeList:
+			value := field.Get(cfg)
+			if value == "" {
 				fmt.Println("(none)")
-			} else {
-				for _, m := range cfg.UnavailableModels {
-					fmt.Println(m)
+			}
+			for _, item := range strings.Split(value, ",") {
+				if item != "" {
+					fmt.Println(item)
 				}
 			}
 		default:
-			PrintError(fmt.Sprintf("unknown config key: %s", key))
-			return fmt.Errorf("unknown key")
+			fmt.Println(field.Get(cfg))
 		}
 		return nil
 	},
@@ -76,9 +103,22 @@ var getCmd = &cobra.Command{
 var setCmd = &cobra.Command{
 	Use:   "set <key> <value>",
 	Short: "Set a configuration value",
-	Args:  cobra.ExactArgs(2),
+	Long: `Set a configuration value in the user config file.
+
+Values are checked before saving: numbers must be in range, URLs must be
+http or https, and keys with a fixed set of values reject anything else.
+List values are comma-separated. Run 'openrouter config list-keys' to see
+every key.`,
+	Args:              cobra.ExactArgs(2),
+	ValidArgsFunction: completeConfigSet,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		field, err := config.LookupField(args[0])
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		cfg, err := config.LoadUser()
 		if err != nil && err != config.ErrNoAPIKey {
 			PrintError(err.Error())
 			return err
@@ -87,47 +127,17 @@ var setCmd = &cobra.Command{
 			cfg = config.DefaultConfig()
 		}
 
-		key := args[0]
 		value := args[1]
-
-		switch key {
-		case "api_key":
-			cfg.APIKey = value
-		case "default_model":
-			cfg.DefaultModel = value
-		case "default_temperature":
-			var temp float64
-			_, err := fmt.Sscanf(value, "%f", &temp)
-			if err != nil {
-				PrintError("temperature must be a number")
+		if field.Key == "default_model" {
+			// Aliases are stored as typed so later changes to them apply
+			if _, err := cfg.ResolveModel(value); err != nil {
+				PrintError(err.Error())
 				return err
 			}
-			cfg.DefaultTemp = temp
-		case "default_max_tokens":
-			var tokens int
-			_, err := fmt.Sscanf(value, "%d", &tokens)
-			if err != nil {
-				PrintError("max_tokens must be an integer")
-				return err
-			}
-			cfg.DefaultMaxTokens = tokens
-		case "output_format":
-			if value != "pretty" && value != "raw" && value != "json" {
-				PrintError("output_format must be: pretty, raw, or json")
-				return fmt.Errorf("invalid format")
-			}
-			cfg.OutputFormat = value
-		case "timeout":
-			var timeout int
-			_, err := fmt.Sscanf(value, "%d", &timeout)
-			if err != nil {
-				PrintError("timeout must be an integer (seconds)")
-				return err
-			}
-			cfg.Timeout = timeout
-		default:
-			PrintError(fmt.Sprintf("unknown config key: %s", key))
-			return fmt.Errorf("unknown key")
+		}
+		if err := field.Set(cfg, value); err != nil {
+			PrintError(fmt.Sprintf("invalid value for %s: %v", field.Key, err))
+			return err
 		}
 
 		if err := config.Save(cfg); err != nil {
@@ -135,17 +145,87 @@ var setCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("✓ Set %s = %s\n", key, value)
+		switch {
+		case field.Key == "api_key":
+			value = maskAPIKey(value)
+		case field.Secret:
+			value = maskSecret(value)
+		case field.Key == "default_model":
+			value += describeResolved(cfg, value)
+		}
+		fmt.Printf("✓ Set %s = %s\n", field.Key, value)
+		return nil
+	},
+}
+
+var unsetCmd = &cobra.Command{
+	Use:               "unset <key>",
+	Short:             "Reset a configuration value to its default",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeConfigKeys,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		field, err := config.LookupField(args[0])
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		cfg, err := config.LoadUser()
+		if err != nil && err != config.ErrNoAPIKey {
+			PrintError(err.Error())
+			return err
+		}
+		if cfg == nil {
+			cfg = config.DefaultConfig()
+		}
+
+		field.Unset(cfg)
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Unset %s\n", field.Key)
+		return nil
+	},
+}
+
+var listKeysCmd = &cobra.Command{
+	Use:   "list-keys",
+	Short: "List every configuration key",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		defaults := config.DefaultConfig()
+		for _, field := range config.Fields() {
+			desc := field.Description
+			if len(field.Choices) > 0 && field.Type != config.TypeBool {
+				desc += " (" + strings.Join(field.Choices, ", ") + ")"
+			}
+			if def := field.Get(defaults); def != "" {
+				desc += " [default: " + def + "]"
+			}
+			fmt.Printf("%-26s %-7s %s\n", field.Key, field.Type, desc)
+		}
 		return nil
 	},
 }
 
 var addUnavailableCmd = &cobra.Command{
-	Use:   "add-unavailable <model_id>",
+	Use:   "add-unavailable <model_id|glob>",
 	Short: "Mark a model as unavailable (won't appear in list)",
-	Args:  cobra.ExactArgs(1),
+	Long: `Mark a model as unavailable so it is hidden from 'openrouter list'.
+
+The ID may be a glob: * matches any characters and ? matches one, so
+'qwen/*:free' hides every free Qwen model. Use --for to have the entry
+expire, e.g. while a provider is down, and --reason to note why.
+
+Examples:
+  openrouter config add-unavailable qwen/qwen3-next-80b-a3b-instruct:free
+  openrouter config add-unavailable 'qwen/*:free' --reason "rate limited"
+  openrouter config add-unavailable mistralai/mistral-large --for 7d`,
+	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		cfg, err := config.LoadUser()
 		if err != nil && err != config.ErrNoAPIKey {
 			PrintError(err.Error())
 			return err
@@ -154,8 +234,20 @@ var addUnavailableCmd = &cobra.Command{
 			cfg = config.DefaultConfig()
 		}
 
-		modelID := args[0]
-		if err := cfg.AddUnavailableModel(modelID); err != nil {
+		modelID, err := resolveModel(cfg, args[0])
+		if err != nil {
+			return err
+		}
+		entry := config.UnavailableModel{ID: modelID, Reason: unavailableReason}
+		if unavailableFor != "" {
+			until, err := config.ParseExpiry(unavailableFor)
+			if err != nil {
+				PrintError(err.Error())
+				return err
+			}
+			entry.Until = &until
+		}
+		if err := cfg.AddUnavailableModel(entry); err != nil {
 			PrintError(err.Error())
 			return err
 		}
@@ -165,17 +257,17 @@ var addUnavailableCmd = &cobra.Command{
 			return err
 		}
 
-		fmt.Printf("✓ Marked %s as unavailable\n", modelID)
+		fmt.Printf("✓ Marked %s as unavailable%s\n", modelID, describeUnavailable(entry))
 		return nil
 	},
 }
 
 var removeUnavailableCmd = &cobra.Command{
-	Use:   "remove-unavailable <model_id>",
+	Use:   "remove-unavailable <model_id|glob>",
 	Short: "Remove a model from the unavailable list",
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		cfg, err := config.LoadUser()
 		if err != nil && err != config.ErrNoAPIKey {
 			PrintError(err.Error())
 			return err
@@ -220,17 +312,110 @@ var listUnavailableCmd = &cobra.Command{
 
 		fmt.Println("Unavailable models (filtered from 'openrouter list'):")
 		for i, m := range cfg.UnavailableModels {
-			fmt.Printf("  %d. %s\n", i+1, m)
+			fmt.Printf("  %d. %s%s\n", i+1, m.ID, describeUnavailable(m))
 		}
 		return nil
 	},
 }
 
+var pruneUnavailableCmd = &cobra.Command{
+	Use:   "prune-unavailable",
+	Short: "Drop expired entries and models no longer in the catalog",
+	Long: `Remove unavailable-model entries that are no longer needed.
+
+Expired entries are removed, as are model IDs that no longer appear in the
+/models catalog. Glob entries are kept, since they may match future models.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		// The catalog comes from the effective api_base_url, but only the
+		// user file is rewritten
+		effective, err := GetConfig()
+		if err == config.ErrNoAPIKey {
+			PrintSetupError()
+			return err
+		} else if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		client := api.NewClient(effective.APIBaseURL, effective.APIKey, effective.Timeout)
+		models, err := client.ListModels()
+		if err != nil {
+			if apiErr, ok := err.(*api.APIError); ok {
+				PrintAPIError(apiErr)
+			} else {
+				PrintError(err.Error())
+			}
+			return err
+		}
+		ids := make([]string, len(models))
+		for i, m := range models {
+			ids[i] = m.ID
+		}
+
+		removed := cfg.PruneUnavailable(ids)
+		if len(removed) == 0 {
+			fmt.Println("Nothing to prune.")
+			return nil
+		}
+		for _, m := range removed {
+			why := "not in catalog"
+			if m.Expired() {
+				why = "expired"
+			}
+			fmt.Printf("  - %s (%s)\n", m.ID, why)
+		}
+		if pruneDryRun {
+			fmt.Printf("Would remove %d entries (dry run)\n", len(removed))
+			return nil
+		}
+
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Removed %d entries from the unavailable list\n", len(removed))
+		return nil
+	},
+}
+
+// describeUnavailable formats an entry's reason and expiry for display
+func describeUnavailable(m config.UnavailableModel) string {
+	var parts []string
+	if m.Reason != "" {
+		parts = append(parts, m.Reason)
+	}
+	if m.Until != nil {
+		if m.Expired() {
+			parts = append(parts, "expired "+m.Until.Local().Format("2006-01-02 15:04"))
+		} else {
+			parts = append(parts, "until "+m.Until.Local().Format("2006-01-02 15:04"))
+		}
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// suggestUnavailable tells the user how to hide a model no provider could serve
+func suggestUnavailable(modelID string, apiErr *api.APIError) {
+	fmt.Fprintf(os.Stderr, "\n%s %s appears to be unavailable. To hide it from 'openrouter list' for a week:\n",
+		color.YellowString("Hint:"), modelID)
+	fmt.Fprintf(os.Stderr, "  openrouter config add-unavailable %s --for 7d --reason %q\n", modelID, apiErr.Message)
+}
+
 var showCmd = &cobra.Command{
 	Use:   "show",
 	Short: "Show all configuration settings",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg, err := config.Load()
+		cfg, err := loadConfig()
 		if err != nil && err != config.ErrNoAPIKey {
 			PrintError(err.Error())
 			return err
@@ -238,24 +423,74 @@ var showCmd = &cobra.Command{
 		if cfg == nil {
 			cfg = config.DefaultConfig()
 		}
+		printConfigWarnings(cfg)
 
 		fmt.Println("Configuration:")
-		fmt.Printf("  API Key: %s\n", maskAPIKey(cfg.APIKey))
-		fmt.Printf("  Default Model: %s\n", cfg.DefaultModel)
-		fmt.Printf("  Default Temperature: %v\n", cfg.DefaultTemp)
-		fmt.Printf("  Default Max Tokens: %d\n", cfg.DefaultMaxTokens)
-		fmt.Printf("  Output Format: %s\n", cfg.OutputFormat)
-		fmt.Printf("  API Base URL: %s\n", cfg.APIBaseURL)
-		fmt.Printf("  Timeout: %d seconds\n", cfg.Timeout)
-		fmt.Printf("  Unavailable Models: %d\n", len(cfg.UnavailableModels))
-		if len(cfg.UnavailableModels) > 0 {
-			fmt.Println("    " + strings.Join(cfg.UnavailableModels, ", "))
-		}
-		fmt.Printf("  Config File: %s\n", config.GetConfigPath())
+		defaults := config.DefaultConfig()
+		for _, field := range config.Fields() {
+			switch {
+			case field.Key == "api_key":
+				// One line covers whichever key backend is in use
+				keyDisplay, keySource := describeAPIKey(cfg)
+				printSetting(cfg, field.Key, keySource, keyDisplay)
+			case field.KeySource:
+			case field.Key == "current_profile":
+				if cfg.ActiveProfile != "" {
+					printSetting(cfg, field.Key, field.Key, cfg.ActiveProfile)
+				}
+			case field.IsZero(cfg) && field.IsZero(defaults):
+				// Skip optional settings that are not in use
+			default:
+				printSetting(cfg, field.Key, field.Key, field.Get(cfg))
+			}
+		}
+		fmt.Printf("  %-*s %s\n", settingWidth, "config file:", config.GetConfigPath())
+		for _, path := range config.FindProjectConfigs() {
+			fmt.Printf("  %-*s %s\n", settingWidth, "project config:", path)
+		}
 		return nil
 	},
 }
 
+// settingWidth aligns the values printed by 'config show'
+const settingWidth = 26
+
+// printSetting prints one line of 'config show', with its origin when --origin is set
+func printSetting(cfg *config.Config, label, key, value string) {
+	if showOrigin {
+		fmt.Printf("  %-*s %s %s\n", settingWidth, label+":", value, color.HiBlackString("(%s)", cfg.Origin(key)))
+		return
+	}
+	fmt.Printf("  %-*s %s\n", settingWidth, label+":", value)
+}
+
+// completeConfigKeys completes the key name argument
+func completeConfigKeys(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return config.FieldNames(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeConfigSet completes the key name, then its allowed values
+func completeConfigSet(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) == 1 {
+		if field, err := config.LookupField(args[0]); err == nil && len(field.Choices) > 0 {
+			return field.Choices, cobra.ShellCompDirectiveNoFileComp
+		}
+		return nil, cobra.ShellCompDirectiveDefault
+	}
+	return completeConfigKeys(cmd, args, toComplete)
+}
+
+// maskSecret hides a secret value other than the plain API key
+func maskSecret(value string) string {
+	if value == "" {
+		return "(not set)"
+	}
+	return "(hidden)"
+}
+
 func maskAPIKey(key string) string {
 	if key == "" {
 		return "(not set)"
@@ -269,8 +504,21 @@ func maskAPIKey(key string) string {
 func init() {
 	configCmd.AddCommand(getCmd)
 	configCmd.AddCommand(setCmd)
+	configCmd.AddCommand(unsetCmd)
+	configCmd.AddCommand(listKeysCmd)
 	configCmd.AddCommand(addUnavailableCmd)
 	configCmd.AddCommand(removeUnavailableCmd)
 	configCmd.AddCommand(listUnavailableCmd)
+	configCmd.AddCommand(pruneUnavailableCmd)
 	configCmd.AddCommand(showCmd)
+	configCmd.AddCommand(profileCmd)
+	configCmd.AddCommand(setKeyCmd)
+	configCmd.AddCommand(doctorCmd)
+	configCmd.AddCommand(migrateCmd)
+	configCmd.AddCommand(editCmd)
+
+	showCmd.Flags().BoolVar(&showOrigin, "origin", false, "Show which config layer each value came from")
+	addUnavailableCmd.Flags().StringVar(&unavailableFor, "for", "", "Expire the entry after a duration (e.g. 12h, 7d, 2w)")
+	addUnavailableCmd.Flags().StringVar(&unavailableReason, "reason", "", "Why the model is unavailable")
+	pruneUnavailableCmd.Flags().BoolVar(&pruneDryRun, "dry-run", false, "Show what would be removed without saving")
 }
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+)
+
+func TestConfigShowOrigin(t *testing.T) {
+	dir := t.TempDir()
+	t.Chdir(dir)
+	userPath := filepath.Join(dir, "config.yaml")
+	projectPath := filepath.Join(dir, config.ProjectConfigName)
+	files := map[string]string{
+		userPath: `api_key: sk-or-v1-user
+default_max_tokens: 100
+timeout: 30
+current_profile: team
+profiles:
+  team:
+    default_model: team/model
+    api_base_url: http://localhost:8080
+`,
+		projectPath: "timeout: 20\noutput_format: yaml\n",
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("OPENROUTER_CONFIG", userPath)
+	t.Setenv("OPENROUTER_PROFILE", "")
+	t.Setenv("OPENROUTER_API_KEY", "sk-or-v1-env")
+	t.Setenv("OPENROUTER_DEFAULT_MODEL", "env/model")
+
+	noColor := color.NoColor
+	color.NoColor = true
+	showOrigin = true
+	apiKey = "sk-or-v1-flag"
+	defer func() { color.NoColor = noColor; showOrigin = false; apiKey = "" }()
+
+	out := captureStdout(t, func() {
+		if err := showCmd.RunE(showCmd, nil); err != nil {
+			t.Error(err)
+		}
+	})
+
+	// Each layer overrides the ones before it
+	for _, want := range []string{
+		"default_temperature:       1 (default)",
+		"default_max_tokens:        100 (user: " + userPath + ")",
+		"output_format:             yaml (project: " + projectPath + ")",
+		"timeout:                   20 (project: " + projectPath + ")",
+		"api_base_url:              http://localhost:8080 (profile: team)",
+		"default_model:             env/model (env: OPENROUTER_DEFAULT_MODEL)",
+		"api_key:                   " + maskAPIKey("sk-or-v1-flag") + " (flag: --api-key)",
+		"current_profile:           team (user: " + userPath + ")",
+		"project config:            " + projectPath,
+	} {
+		if !strings.Contains(out, "  "+want+"\n") {
+			t.Errorf("output is missing %q:\n%s", want, out)
+		}
+	}
+}
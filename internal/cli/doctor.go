@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check the configuration for problems",
+	Long: `Check the configuration and the connection to the API.
+
+The doctor checks that:
+  - config files parse, with no unknown keys and a current layout
+  - the user config file is only readable by you (mode 0600)
+  - every setting is valid
+  - an API key is configured and can be read from its backend
+  - the configured api_base_url answers
+  - default_model exists in the model catalog
+
+It exits with an error if any check fails. Warnings do not fail.`,
+	Args: cobra.NoArgs,
+	RunE: runDoctor,
+}
+
+// doctorReport prints check results and counts problems
+type doctorReport struct {
+	failures int
+	warnings int
+}
+
+func (r *doctorReport) ok(format string, args ...any) {
+	fmt.Printf("  %s %s\n", color.GreenString("✓"), fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) warn(format string, args ...any) {
+	r.warnings++
+	fmt.Printf("  %s %s\n", color.YellowString("!"), fmt.Sprintf(format, args...))
+}
+
+func (r *doctorReport) fail(format string, args ...any) {
+	r.failures++
+	fmt.Printf("  %s %s\n", color.RedString("✗"), fmt.Sprintf(format, args...))
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	report := &doctorReport{}
+	fmt.Println("Checking configuration:")
+
+	cfg, err := loadConfig()
+	if err != nil {
+		report.fail("%v", err)
+		return report.finish()
+	}
+
+	configPath := config.GetConfigPath()
+	if info, err := os.Stat(configPath); err != nil {
+		report.warn("No config file at %s (using defaults)", configPath)
+	} else {
+		report.ok("Config file %s", configPath)
+		checkPermissions(report, configPath, info)
+	}
+	for _, path := range config.FindProjectConfigs() {
+		report.ok("Project config %s", path)
+	}
+	for _, warning := range cfg.Warnings {
+		report.warn("%s", warning)
+	}
+
+	invalid := 0
+	for _, field := range config.Fields() {
+		if err := field.Validate(cfg); err != nil {
+			report.fail("%s: %v (from %s)", field.Key, err, cfg.Origin(field.Key))
+			invalid++
+		}
+	}
+	for _, problem := range cfg.CheckAliases() {
+		report.fail("%s", problem)
+		invalid++
+	}
+	if invalid == 0 {
+		report.ok("All settings are valid")
+	}
+
+	checkAPIKey(report, cfg)
+
+	// /models is public, so the catalog can be checked even without a key
+	client := api.NewClient(cfg.APIBaseURL, cfg.APIKey, cfg.Timeout)
+	models, err := client.ListModels()
+	var apiErr *api.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		report.fail("%s answered HTTP %d: %s", cfg.APIBaseURL, apiErr.StatusCode, apiErr.Message)
+	case err != nil:
+		report.fail("Cannot reach %s: %v", cfg.APIBaseURL, err)
+	default:
+		report.ok("Reached %s (%d models)", cfg.APIBaseURL, len(models))
+		checkDefaultModel(report, cfg, models)
+	}
+
+	return report.finish()
+}
+
+// checkPermissions warns when a file that may hold secrets is readable by others
+func checkPermissions(report *doctorReport, path string, info os.FileInfo) {
+	// Windows does not use Unix permission bits
+	if runtime.GOOS == "windows" {
+		return
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		report.warn("%s has mode %04o; run: chmod 600 %s", path, perm, path)
+	}
+}
+
+// checkAPIKey checks that a key is configured and its backend can supply it
+func checkAPIKey(report *doctorReport, cfg *config.Config) {
+	backend := cfg.APIKeyBackend()
+	if backend == "" {
+		report.fail("No API key configured (run 'openrouter config set-key')")
+		return
+	}
+
+	_, source := describeAPIKey(cfg)
+	if backend == config.BackendFile {
+		if info, err := os.Stat(config.ExpandHome(cfg.APIKeyFile)); err == nil {
+			checkPermissions(report, cfg.APIKeyFile, info)
+		}
+	}
+	if err := cfg.ResolveAPIKey(promptPassphrase); err != nil {
+		report.fail("API key (%s backend, from %s): %v", backend, cfg.Origin(source), err)
+		return
+	}
+	report.ok("API key configured (%s backend, from %s)", backend, cfg.Origin(source))
+}
+
+// checkDefaultModel checks default_model against the fetched catalog
+func checkDefaultModel(report *doctorReport, cfg *config.Config, models []api.Model) {
+	id, err := cfg.ResolveModel(cfg.DefaultModel)
+	if err != nil {
+		// Already reported with the other alias problems
+		return
+	}
+	for _, model := range models {
+		if model.ID != id {
+			continue
+		}
+		if cfg.IsModelUnavailable(model.ID) {
+			report.warn("Default model %s is marked unavailable", model.ID)
+			return
+		}
+		report.ok("Default model %s is in the catalog", model.ID)
+		return
+	}
+	report.fail("Default model %s is not in the catalog (see 'openrouter list')", id)
+}
+
+// finish prints a summary and returns an error if any check failed
+func (r *doctorReport) finish() error {
+	fmt.Println()
+	if r.failures > 0 {
+		err := fmt.Errorf("%d problem(s) found", r.failures)
+		PrintError(fmt.Sprintf("%v, %d warning(s)", err, r.warnings))
+		return err
+	}
+	if r.warnings > 0 {
+		fmt.Printf("No problems found, %d warning(s)\n", r.warnings)
+		return nil
+	}
+	fmt.Println("✓ No problems found")
+	return nil
+}
@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// editMarker starts the comment lines 'config edit' adds to explain problems
+// They are removed before the file is checked or saved
+const editMarker = "#! "
+
+var editCmd = &cobra.Command{
+	Use:   "edit",
+	Short: "Edit the user config file in $EDITOR",
+	Long: `Open the user config file in $VISUAL or $EDITOR (vi by default).
+
+The file is edited as a temporary copy. When you save and quit, the copy is
+checked for YAML errors, unknown keys and invalid values. If there are
+problems, the editor is reopened with them listed as comments at the top;
+quit without changing anything to give up and keep the original file. A
+valid copy replaces the config file.
+
+If the config file does not exist it is created with the default settings.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := config.GetConfigPath()
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			if err := config.Save(config.DefaultConfig()); err != nil {
+				PrintError(fmt.Sprintf("failed to save config: %v", err))
+				return err
+			}
+			fmt.Printf("Created %s with default settings\n", path)
+		}
+
+		changed, err := editConfigFile(path)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		if !changed {
+			fmt.Println("No changes made.")
+			return nil
+		}
+
+		fmt.Printf("✓ Saved %s\n", path)
+		return nil
+	},
+}
+
+// editConfigFile edits a copy of path until it is valid or the user gives up,
+// then replaces path with it. It reports whether the file changed.
+func editConfigFile(path string) (bool, error) {
+	original, err := os.ReadFile(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// Keep the temporary copy next to the config so the final rename is atomic
+	tmp, err := os.CreateTemp(filepath.Dir(path), "config-*.yaml")
+	if err != nil {
+		return false, fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	tmp.Close()
+	defer os.Remove(tmpPath)
+
+	content := original
+	for {
+		if err := os.WriteFile(tmpPath, content, 0600); err != nil {
+			return false, fmt.Errorf("failed to write temporary file: %w", err)
+		}
+		if err := runEditor(tmpPath); err != nil {
+			return false, err
+		}
+
+		edited, err := os.ReadFile(tmpPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to read edited file: %w", err)
+		}
+		if bytes.Equal(edited, content) {
+			if bytes.Equal(content, original) {
+				return false, nil
+			}
+			// Quitting without touching the annotated copy gives up
+			return false, fmt.Errorf("config has problems; %s was left unchanged", path)
+		}
+
+		edited = stripEditNotes(edited)
+		problems := config.Lint(edited, path)
+		if len(problems) == 0 {
+			if bytes.Equal(edited, original) {
+				return false, nil
+			}
+			return true, replaceFile(path, tmpPath, edited)
+		}
+		// Check again with the notes in place so line numbers match what the
+		// editor shows; the notes are comments, so the problems are the same
+		content = addEditNotes(edited, config.Lint(addEditNotes(edited, problems), path))
+	}
+}
+
+// runEditor opens path in $VISUAL or $EDITOR and waits for it to exit
+// The editor runs through the shell so values such as "code --wait" work
+func runEditor(path string) error {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+
+	var cmd *exec.Cmd
+	switch {
+	case runtime.GOOS == "windows" && editor == "":
+		cmd = exec.Command("notepad", path)
+	case runtime.GOOS == "windows":
+		cmd = exec.Command("cmd", "/C", editor+" "+path)
+	default:
+		if editor == "" {
+			editor = "vi"
+		}
+		cmd = exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %w", editor, err)
+	}
+	return nil
+}
+
+// addEditNotes prefixes content with the problems found, as marked comments
+func addEditNotes(content []byte, problems []string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(editMarker + "This config was not saved because of these problems:\n")
+	for _, problem := range problems {
+		buf.WriteString(editMarker + "  " + problem + "\n")
+	}
+	buf.WriteString(editMarker + "Fix them and save, or quit without changes to keep the original file.\n")
+	buf.Write(content)
+	return buf.Bytes()
+}
+
+// stripEditNotes removes the comment lines added by addEditNotes
+func stripEditNotes(content []byte) []byte {
+	lines := strings.SplitAfter(string(content), "\n")
+	var kept strings.Builder
+	for _, line := range lines {
+		if !strings.HasPrefix(line, editMarker) {
+			kept.WriteString(line)
+		}
+	}
+	return []byte(kept.String())
+}
+
+// replaceFile writes data to tmpPath and renames it over path, keeping
+// path's permissions
+func replaceFile(path, tmpPath string, data []byte) error {
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.WriteFile(tmpPath, data, mode); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Chmod(tmpPath, mode); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
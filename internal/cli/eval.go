@@ -0,0 +1,333 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/batch"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/eval"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Eval command flags
+	evalModels      []string
+	evalJudge       string
+	evalConcurrency int
+	evalRetries     int
+	evalJSON        bool
+	evalJUnit       string
+	evalMinPassRate float64
+)
+
+// evalHeaders are the columns of the per-model summary
+var evalHeaders = []string{
+	"model", "cases", "passed", "failed", "errors", "pass_rate", "cost", "total_tokens", "latency_avg_ms", "latency_p95_ms",
+}
+
+var evalCmd = &cobra.Command{
+	Use:   "eval <suite.yaml>",
+	Short: "Score models against a suite of test cases",
+	Long: `Run every case in a suite against each candidate model, check the answers
+and report pass rates, cost and latency per model.
+
+A suite is a YAML file:
+
+  name: capitals
+  models: [openai/gpt-4o-mini, fast]
+  judge: openai/gpt-4o          # grades judge rubrics
+  temperature: 0
+  cases:
+    - id: france
+      input: What is the capital of France? Answer in one word.
+      expect:
+        contains: Paris
+        regex: '^\W*Paris\W*$'
+    - id: json
+      input: Give the capital of Italy as JSON with a "city" field.
+      expect:
+        json_schema:
+          type: object
+          required: [city]
+          properties: {city: {type: string}}
+    - id: tone
+      input: Explain recursion to a child.
+      expect:
+        not_contains: [stack frame]
+        judge: Uses a simple everyday analogy and no jargon.
+
+A case passes when its answer meets every expectation. The command exits
+with an error when any model's pass rate is under --min-pass-rate (default:
+every case must pass), so it can gate CI; point api_base_url at a local
+stand-in server to run it without network access.
+
+Examples:
+  openrouter eval suite.yaml
+  openrouter eval suite.yaml -m fast -m sonnet --judge openai/gpt-4o
+  openrouter eval suite.yaml --junit report.xml --min-pass-rate 0.9
+  openrouter eval suite.yaml --json | jq '.models'`,
+	Args: cobra.ExactArgs(1),
+	RunE: runEval,
+}
+
+func runEval(cmd *cobra.Command, args []string) error {
+	cfg, err := GetConfig()
+	if err == config.ErrNoAPIKey {
+		PrintSetupError()
+		return err
+	} else if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	var shortcut OutputFormat
+	if evalJSON {
+		shortcut = FormatJSON
+	}
+	format, err := ResolveOutputFormat(cfg, shortcut)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	if evalConcurrency < 1 {
+		PrintError("--concurrency must be at least 1")
+		return fmt.Errorf("invalid flags")
+	}
+	if evalMinPassRate < 0 || evalMinPassRate > 1 {
+		PrintError("--min-pass-rate must be between 0 and 1")
+		return fmt.Errorf("invalid flags")
+	}
+
+	suite, err := eval.LoadSuite(args[0])
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	models := suite.Models
+	if len(evalModels) > 0 {
+		models = evalModels
+	}
+	if len(models) == 0 {
+		PrintError("no models to evaluate: list them under models: in the suite or pass -m")
+		return fmt.Errorf("no models")
+	}
+	for _, m := range models {
+		if _, err := resolveModel(cfg, m); err != nil {
+			return err
+		}
+	}
+	judge := suite.Judge
+	if evalJudge != "" {
+		judge = evalJudge
+	}
+	if suite.NeedsJudge() {
+		if judge == "" {
+			PrintError("the suite has judge rubrics but no judge model: set judge: in the suite or pass --judge")
+			return fmt.Errorf("no judge model")
+		}
+		if _, err := resolveModel(cfg, judge); err != nil {
+			return err
+		}
+	}
+
+	client, err := newChatClient(cfg)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	runner := &eval.Runner{
+		Client:      client,
+		Concurrency: evalConcurrency,
+		Retries:     evalRetries,
+		Backoff:     time.Second,
+		Request: func(item batch.Item) (*api.ChatCompletionRequest, error) {
+			return evalRequest(cfg, item)
+		},
+		Cost: catalogPricer(client),
+		Progress: func(done, total int, r eval.CaseResult) {
+			status := "answered"
+			if r.Error != "" {
+				status = "failed: " + r.Error
+			}
+			fmt.Fprintf(os.Stderr, "[%d/%d] %s / %s %s (%s)\n", done, total, r.Model, r.Case, status, seconds(r.DurationMS))
+		},
+	}
+	report, err := runner.Run(suite, models, judge)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	if evalJUnit != "" {
+		if err := writeJUnitFile(report, evalJUnit); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+	}
+	if err := formatEvalReport(report, format); err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	if below := report.BelowPassRate(evalMinPassRate); len(below) > 0 {
+		var names []string
+		for _, s := range below {
+			names = append(names, fmt.Sprintf("%s (%s)", s.Model, percent(s.PassRate)))
+		}
+		err := fmt.Errorf("pass rate below %s", percent(evalMinPassRate))
+		PrintError(fmt.Sprintf("%s: %s", err, strings.Join(names, ", ")))
+		return err
+	}
+	return nil
+}
+
+// evalRequest builds the request for a suite item, using the config
+// defaults for values the suite doesn't set
+func evalRequest(cfg *config.Config, item batch.Item) (*api.ChatCompletionRequest, error) {
+	modelID, err := cfg.ResolveModel(item.Model)
+	if err != nil {
+		return nil, err
+	}
+	temp := cfg.DefaultTemp
+	if item.Temperature != nil {
+		temp = *item.Temperature
+	}
+	maxTok := cfg.DefaultMaxTokens
+	if item.MaxTokens != nil {
+		maxTok = *item.MaxTokens
+	}
+	return &api.ChatCompletionRequest{
+		Model:       modelID,
+		Messages:    item.ChatMessages(),
+		Temperature: &temp,
+		MaxTokens:   maxTok,
+		Provider:    providerPreferences(cfg.Routing),
+	}, nil
+}
+
+// writeJUnitFile writes the report as JUnit XML to path
+func writeJUnitFile(report *eval.Report, path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create JUnit report: %w", err)
+	}
+	if err := report.WriteJUnit(f); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return f.Close()
+}
+
+// formatEvalReport writes the report in the given format
+// JSON and YAML hold the whole report, NDJSON one line per case result, and
+// CSV and TSV the per-model summary.
+func formatEvalReport(report *eval.Report, format OutputFormat) error {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		fmt.Println(string(data))
+		return nil
+	case FormatYAML:
+		return writeYAML(os.Stdout, report)
+	case FormatNDJSON:
+		return writeNDJSON(os.Stdout, report.Results)
+	case FormatCSV, FormatTSV:
+		rows := make([][]string, len(report.Models))
+		for i, s := range report.Models {
+			rows[i] = []string{
+				s.Model, strconv.Itoa(s.Cases), strconv.Itoa(s.Passed), strconv.Itoa(s.Failed), strconv.Itoa(s.Errors),
+				strconv.FormatFloat(s.PassRate, 'f', -1, 64), strconv.FormatFloat(s.Cost, 'f', -1, 64),
+				strconv.Itoa(s.TotalTokens), strconv.FormatInt(s.LatencyAvgMS, 10), strconv.FormatInt(s.LatencyP95MS, 10),
+			}
+		}
+		return writeDelimited(os.Stdout, evalHeaders, rows, delimiter(format))
+	}
+
+	printEvalTable(report)
+	return nil
+}
+
+// printEvalTable prints the per-model summary and every failed case
+func printEvalTable(report *eval.Report) {
+	cases := 0
+	if len(report.Models) > 0 {
+		cases = report.Models[0].Cases
+	}
+	title := fmt.Sprintf("%d case(s) x %d model(s)", cases, len(report.Models))
+	if report.Suite != "" {
+		title = report.Suite + ": " + title
+	}
+	fmt.Println(color.CyanString(title))
+	fmt.Println()
+
+	headers := []string{"MODEL", "PASSED", "RATE", "COST", "TOKENS", "AVG", "P95"}
+	rows := make([][]string, len(report.Models))
+	for i, s := range report.Models {
+		rows[i] = []string{
+			s.Model, fmt.Sprintf("%d/%d", s.Passed, s.Cases), percent(s.PassRate), fmt.Sprintf("$%.4f", s.Cost),
+			strconv.Itoa(s.TotalTokens), seconds(s.LatencyAvgMS), seconds(s.LatencyP95MS),
+		}
+	}
+	widths := make([]int, len(headers))
+	for i, h := range headers {
+		widths[i] = len(h)
+		for _, row := range rows {
+			widths[i] = max(widths[i], len(row[i]))
+		}
+	}
+	fmt.Println(formatTableRow(headers, widths, "  "))
+	for _, row := range rows {
+		fmt.Println(formatTableRow(row, widths, "  "))
+	}
+
+	var failed []eval.CaseResult
+	for _, r := range report.Results {
+		if !r.Passed {
+			failed = append(failed, r)
+		}
+	}
+	if len(failed) > 0 {
+		fmt.Println()
+		fmt.Println("Failures:")
+		for _, r := range failed {
+			fmt.Printf("  %s %s / %s\n", color.RedString("✗"), r.Model, r.Case)
+			for _, f := range r.Failures() {
+				fmt.Printf("      %s\n", f)
+			}
+		}
+	}
+	if report.Judge != "" {
+		fmt.Println()
+		fmt.Printf("Judged by %s for $%.4f\n", report.Judge, report.JudgeCost)
+	}
+}
+
+func percent(rate float64) string {
+	return strconv.FormatFloat(rate*100, 'f', -1, 64) + "%"
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
+
+func init() {
+	evalCmd.Flags().StringArrayVarP(&evalModels, "model", "m", nil, "Model ID or alias to evaluate, instead of the suite's models (repeatable)")
+	evalCmd.RegisterFlagCompletionFunc("model", completeAliases)
+	evalCmd.Flags().StringVar(&evalJudge, "judge", "", "Model that grades judge rubrics (overrides the suite's judge)")
+	evalCmd.RegisterFlagCompletionFunc("judge", completeAliases)
+	evalCmd.Flags().IntVarP(&evalConcurrency, "concurrency", "j", 4, "Number of requests to send at once")
+	evalCmd.Flags().IntVar(&evalRetries, "retries", 2, "Retries for rate-limited, timed-out or failed requests")
+	evalCmd.Flags().BoolVar(&evalJSON, "json", false, "Output the full report as JSON")
+	evalCmd.Flags().StringVar(&evalJUnit, "junit", "", "Also write the report as JUnit XML to this file")
+	evalCmd.Flags().Float64Var(&evalMinPassRate, "min-pass-rate", 1, "Fail unless every model passes at least this fraction of cases (0-1)")
+}
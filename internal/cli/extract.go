@@ -0,0 +1,93 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/markdown"
+)
+
+// selectCodeBlocks extracts the fenced code blocks from a reply, keeping only
+// blocks in lang when it is set
+func selectCodeBlocks(content, lang string) []markdown.CodeBlock {
+	blocks := markdown.ExtractCodeBlocks(content)
+	if lang == "" {
+		return blocks
+	}
+
+	want := markdown.CanonicalLang(lang)
+	filtered := make([]markdown.CodeBlock, 0, len(blocks))
+	for _, b := range blocks {
+		if markdown.CanonicalLang(b.Lang) == want {
+			filtered = append(filtered, b)
+		}
+	}
+	return filtered
+}
+
+// printCodeBlocks writes the content of each block to stdout, separated by blank lines
+func printCodeBlocks(blocks []markdown.CodeBlock) {
+	for i, b := range blocks {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Print(b.Content)
+	}
+}
+
+// saveCodeBlocks writes each block to a file in dir
+// File names come from the block's hint, falling back to snippet-N.<ext>
+// when there is none or another block already uses it.
+// Every target is checked before anything is written so a refused
+// overwrite leaves the directory untouched
+func saveCodeBlocks(blocks []markdown.CodeBlock, dir string, force bool) ([]string, error) {
+	paths := make([]string, len(blocks))
+	seen := make(map[string]bool)
+
+	for i, b := range blocks {
+		name := b.Filename
+		if name == "" {
+			name = b.DefaultFilename(i + 1)
+		}
+
+		// Keep hinted paths inside the target directory
+		clean := filepath.Clean(filepath.FromSlash(name))
+		if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("refusing to write %q outside %s", name, dir)
+		}
+
+		// A repeated name falls back to the first free snippet-N file
+		path := filepath.Join(dir, clean)
+		if seen[path] {
+			renamed := path
+			for n := i + 1; seen[renamed]; n++ {
+				renamed = filepath.Join(dir, b.DefaultFilename(n))
+			}
+			fmt.Fprintf(os.Stderr, "%s code block %d is also named %s; saving it as %s\n",
+				color.YellowString("Warning:"), i+1, name, renamed)
+			path = renamed
+		}
+		seen[path] = true
+
+		if !force {
+			if _, err := os.Stat(path); err == nil {
+				return nil, fmt.Errorf("%s already exists (use --force to overwrite)", path)
+			}
+		}
+		paths[i] = path
+	}
+
+	for i, b := range blocks {
+		if err := os.MkdirAll(filepath.Dir(paths[i]), 0755); err != nil {
+			return nil, fmt.Errorf("failed to create directory: %w", err)
+		}
+		if err := os.WriteFile(paths[i], []byte(b.Content), 0644); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", paths[i], err)
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,84 @@
+package cli
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/markdown"
+)
+
+func TestSaveCodeBlocks(t *testing.T) {
+	noColor := color.NoColor
+	color.NoColor = true
+	defer func() { color.NoColor = noColor }()
+
+	dir := t.TempDir()
+	blocks := []markdown.CodeBlock{
+		{Lang: "go", Filename: "main.go", Content: "package main // 1"},
+		{Lang: "go", Content: "package main // 2"},
+		{Lang: "go", Filename: "main.go", Content: "package main // 3"},
+		{Lang: "go", Filename: "snippet-3.go", Content: "package main // 4"}, // Block 3's fallback
+		{Lang: "go", Filename: "main.go", Content: "package main // 5"},
+	}
+
+	var paths []string
+	stderr := captureStderr(t, func() {
+		var err error
+		paths, err = saveCodeBlocks(blocks, dir, false)
+		if err != nil {
+			t.Error(err)
+		}
+	})
+
+	var names []string
+	for i, p := range paths {
+		names = append(names, filepath.Base(p))
+		data, err := os.ReadFile(p)
+		if err != nil || string(data) != blocks[i].Content {
+			t.Errorf("%s = %q, %v; want %q", p, data, err, blocks[i].Content)
+		}
+	}
+	want := []string{"main.go", "snippet-2.go", "snippet-3.go", "snippet-4.go", "snippet-5.go"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("files = %q, want %q", names, want)
+	}
+
+	for _, warning := range []string{
+		"code block 3 is also named main.go; saving it as " + filepath.Join(dir, "snippet-3.go"),
+		"code block 4 is also named snippet-3.go; saving it as " + filepath.Join(dir, "snippet-4.go"),
+		"code block 5 is also named main.go; saving it as " + filepath.Join(dir, "snippet-5.go"),
+	} {
+		if !strings.Contains(stderr, "Warning: "+warning+"\n") {
+			t.Errorf("stderr is missing %q:\n%s", warning, stderr)
+		}
+	}
+	if n := strings.Count(stderr, "Warning:"); n != 3 {
+		t.Errorf("%d warnings, want 3:\n%s", n, stderr)
+	}
+}
+
+func TestSaveCodeBlocksRefusals(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("keep"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	blocks := []markdown.CodeBlock{
+		{Lang: "python", Content: "print(1)"},
+		{Lang: "go", Filename: "main.go", Content: "package main"},
+	}
+	if _, err := saveCodeBlocks(blocks, dir, false); err == nil || !strings.Contains(err.Error(), "already exists") {
+		t.Fatalf("err = %v, want a refused overwrite", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "snippet-1.py")); err == nil {
+		t.Error("a file was written before the overwrite was refused")
+	}
+
+	outside := []markdown.CodeBlock{{Lang: "sh", Filename: "../evil.sh", Content: "true"}}
+	if _, err := saveCodeBlocks(outside, dir, true); err == nil || !strings.Contains(err.Error(), "outside") {
+		t.Errorf("err = %v, want a refusal to write outside the directory", err)
+	}
+}
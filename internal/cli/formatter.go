@@ -3,11 +3,16 @@ package cli
 import (
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/fatih/color"
 	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/markdown"
+	"github.com/kdevrou/openrouter-cli/internal/util"
 )
 
 // OutputFormat represents the desired output format
@@ -17,8 +22,17 @@ const (
 	FormatPretty OutputFormat = "pretty"
 	FormatRaw    OutputFormat = "raw"
 	FormatJSON   OutputFormat = "json"
+	FormatCSV    OutputFormat = "csv"
+	FormatTSV    OutputFormat = "tsv"
+	FormatYAML   OutputFormat = "yaml"
+	FormatNDJSON OutputFormat = "ndjson"
 )
 
+// chatResponseHeaders are the columns written for chat responses in CSV and TSV
+var chatResponseHeaders = []string{
+	"id", "model", "finish_reason", "prompt_tokens", "completion_tokens", "total_tokens", "content",
+}
+
 // FormatChatResponse formats a chat completion response
 func FormatChatResponse(resp *api.ChatCompletionResponse, format OutputFormat) error {
 	if len(resp.Choices) == 0 {
@@ -41,79 +55,209 @@ func FormatChatResponse(resp *api.ChatCompletionResponse, format OutputFormat) e
 			return fmt.Errorf("failed to marshal response: %w", err)
 		}
 		fmt.Println(string(data))
+	case FormatNDJSON:
+		return writeNDJSON(os.Stdout, []*api.ChatCompletionResponse{resp})
+	case FormatYAML:
+		return writeYAML(os.Stdout, resp)
+	case FormatCSV, FormatTSV:
+		row := []string{
+			resp.ID,
+			resp.Model,
+			choice.FinishReason,
+			strconv.Itoa(resp.Usage.PromptTokens),
+			strconv.Itoa(resp.Usage.CompletionTokens),
+			strconv.Itoa(resp.Usage.TotalTokens),
+			message,
+		}
+		return writeDelimited(os.Stdout, chatResponseHeaders, [][]string{row}, delimiter(format))
 	default: // FormatPretty
-		fmt.Printf("%s\n", message)
+		if shouldRenderMarkdown() {
+			r := markdown.NewRenderer(os.Stdout, util.TerminalWidth())
+			if _, err := io.WriteString(r, message); err != nil {
+				return err
+			}
+			if err := r.Close(); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("%s\n", message)
+		}
 
 		// Print usage stats
+		var stats string
 		if resp.Usage.TotalTokens > 0 {
-			fmt.Printf("\n%s\n",
-				color.CyanString(fmt.Sprintf("Tokens used: %d (prompt: %d, completion: %d)",
-					resp.Usage.TotalTokens,
-					resp.Usage.PromptTokens,
-					resp.Usage.CompletionTokens)))
+			stats = fmt.Sprintf("Tokens used: %d (prompt: %d, completion: %d)",
+				resp.Usage.TotalTokens,
+				resp.Usage.PromptTokens,
+				resp.Usage.CompletionTokens)
+		}
+		if resp.Cached {
+			stats = strings.TrimPrefix(stats+" - cached response", " - ")
+		}
+		if stats != "" {
+			fmt.Printf("\n%s\n", color.CyanString(stats))
 		}
 	}
 
 	return nil
 }
 
+// shouldRenderMarkdown reports whether pretty output should be rendered as
+// styled markdown: only on a color terminal and not disabled with --no-render
+func shouldRenderMarkdown() bool {
+	return renderMarkdownOn(util.IsTerminal(os.Stdout))
+}
+
+// renderMarkdownOn is shouldRenderMarkdown for output that is or isn't a terminal
+func renderMarkdownOn(terminal bool) bool {
+	return terminal && !noRender && !color.NoColor
+}
+
+// ModelListOptions controls how FormatModelList renders the model table
+type ModelListOptions struct {
+	Columns  []string // Column names from ModelColumnNames; empty means DefaultModelColumns
+	NoHeader bool     // Omit the header row and separator line
+	Width    int      // Maximum table width; 0 means use the terminal width
+}
+
 // FormatModelList formats a list of models as a table
-func FormatModelList(models []api.Model, format OutputFormat) error {
-	if format == FormatJSON {
+func FormatModelList(models []api.Model, format OutputFormat, opts ModelListOptions) error {
+	columns := opts.Columns
+	if len(columns) == 0 {
+		columns = DefaultModelColumns
+	}
+
+	switch format {
+	case FormatJSON:
 		data, err := json.MarshalIndent(models, "", "  ")
 		if err != nil {
 			return fmt.Errorf("failed to marshal models: %w", err)
 		}
 		fmt.Println(string(data))
 		return nil
+	case FormatNDJSON:
+		return writeNDJSON(os.Stdout, models)
+	case FormatYAML:
+		return writeYAML(os.Stdout, models)
+	case FormatCSV, FormatTSV:
+		// Delimited output uses the column names as headers and unformatted values
+		var headers []string
+		if !opts.NoHeader {
+			headers = columns
+		}
+		rows := make([][]string, len(models))
+		for r, model := range models {
+			rows[r] = make([]string, len(columns))
+			for i, name := range columns {
+				rows[r][i] = modelColumns[name].Raw(model)
+			}
+		}
+		return writeDelimited(os.Stdout, headers, rows, delimiter(format))
+	}
+
+	width := opts.Width
+	if width <= 0 {
+		width = util.TerminalWidth()
+	}
+
+	// Build the cell values for every row up front so widths can be measured
+	headers := make([]string, len(columns))
+	rows := make([][]string, len(models))
+	for i, name := range columns {
+		headers[i] = modelColumns[name].Header
+	}
+	for r, model := range models {
+		rows[r] = make([]string, len(columns))
+		for i, name := range columns {
+			rows[r][i] = modelColumns[name].Value(model)
+		}
 	}
 
-	// Format as table for pretty/raw output
-	// Print header with wider columns
-	fmt.Printf("%-50s | %-15s | %-35s | %-15s\n",
-		"Model ID", "Context", "Pricing (prompt/completion)", "Modality")
-	fmt.Println(strings.Repeat("-", 120))
+	widths := fitColumnWidths(columns, headers, rows, width, opts.NoHeader)
+	separator := " | "
 
-	for _, model := range models {
-		// Use full model ID without truncation
-		modelID := model.ID
-		if len(modelID) > 50 {
-			modelID = modelID[:47] + "..."
+	if !opts.NoHeader {
+		fmt.Println(formatTableRow(headers, widths, separator))
+		total := len(separator) * (len(widths) - 1)
+		for _, w := range widths {
+			total += w
 		}
+		fmt.Println(strings.Repeat("-", total))
+	}
 
-		// Format context length with commas
-		contextStr := fmt.Sprintf("%d", model.ContextLength)
+	for _, row := range rows {
+		fmt.Println(formatTableRow(row, widths, separator))
+	}
+	return nil
+}
+
+// delimiter returns the field separator for a delimited output format
+func delimiter(format OutputFormat) rune {
+	if format == FormatTSV {
+		return '\t'
+	}
+	return ','
+}
 
-		// Format pricing without truncation
-		pricing := formatPrice(model.Pricing.Prompt) + " / " + formatPrice(model.Pricing.Completion)
-		if len(pricing) > 35 {
-			pricing = pricing[:32] + "..."
+// fitColumnWidths measures each column and shrinks flexible columns until the table fits in maxWidth
+func fitColumnWidths(columns, headers []string, rows [][]string, maxWidth int, noHeader bool) []int {
+	widths := make([]int, len(columns))
+	for i := range columns {
+		if !noHeader {
+			widths[i] = utf8.RuneCountInString(headers[i])
 		}
+		for _, row := range rows {
+			if n := utf8.RuneCountInString(row[i]); n > widths[i] {
+				widths[i] = n
+			}
+		}
+	}
 
-		// Format modality
-		modality := model.Architecture.Modality
-		if modality == "" {
-			modality = "text"
+	total := 3 * (len(widths) - 1)
+	for _, w := range widths {
+		total += w
+	}
+
+	// Narrow the widest flexible column one character at a time
+	const minFlexibleWidth = 12
+	for total > maxWidth {
+		widest := -1
+		for i, name := range columns {
+			if modelColumns[name].Flexible && widths[i] > minFlexibleWidth &&
+				(widest == -1 || widths[i] > widths[widest]) {
+				widest = i
+			}
 		}
+		if widest == -1 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
 
-		fmt.Printf("%-50s | %-15s | %-35s | %-15s\n",
-			modelID,
-			contextStr,
-			pricing,
-			modality)
+	return widths
+}
+
+// formatTableRow pads and truncates cells to the given widths
+func formatTableRow(cells []string, widths []int, separator string) string {
+	parts := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = truncate(cell, widths[i])
+		parts[i] = cell + strings.Repeat(" ", widths[i]-utf8.RuneCountInString(cell))
 	}
-	return nil
+	return strings.TrimRight(strings.Join(parts, separator), " ")
 }
 
-// formatPrice formats a price string for display
-func formatPrice(price string) string {
-	if price == "" || price == "0" {
-		return "free"
+// truncate shortens s to at most width runes, marking the cut with "..."
+func truncate(s string, width int) string {
+	if width <= 0 || utf8.RuneCountInString(s) <= width {
+		return s
 	}
-	if len(price) > 15 {
-		return price[:15] + "..."
+	runes := []rune(s)
+	if width <= 3 {
+		return string(runes[:width])
 	}
-	return price
+	return string(runes[:width-3]) + "..."
 }
 
 // PrintError prints an error in a user-friendly way
@@ -142,6 +286,7 @@ func PrintAPIError(err *api.APIError) {
 // PrintSetupInstructions prints API key setup instructions
 func PrintSetupInstructions() {
 	fmt.Fprintf(os.Stderr, "\n%s\n", color.YellowString("To set up OpenRouter CLI:"))
+	fmt.Fprintf(os.Stderr, "\nRun %s to set up interactively, or:\n", color.CyanString("openrouter init"))
 	fmt.Fprintf(os.Stderr, "\n1. Get an API key from https://openrouter.ai\n")
 	fmt.Fprintf(os.Stderr, "2. Set it using one of:\n")
 	fmt.Fprintf(os.Stderr, "   - Environment variable: %s\n", color.CyanString("export OPENROUTER_API_KEY=sk-..."))
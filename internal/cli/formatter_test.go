@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// captureStdout returns what fn writes to os.Stdout, which is a pipe and so
+// not a terminal while fn runs
+func captureStdout(t *testing.T, fn func()) string {
+	return capture(t, &os.Stdout, fn)
+}
+
+// captureStderr returns what fn writes to os.Stderr
+func captureStderr(t *testing.T, fn func()) string {
+	return capture(t, &os.Stderr, fn)
+}
+
+// capture replaces *file with a pipe while fn runs and returns what was written to it
+func capture(t *testing.T, file **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	saved := *file
+	*file = w
+	defer func() { *file = saved }()
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestPrettyOutputFallsBackToPlainText(t *testing.T) {
+	message := "# Title\n\nSome **bold** text.\n| a | b |\n|---|---|\n| 1 | 2 |"
+	resp := &api.ChatCompletionResponse{
+		ID:      "gen-1",
+		Model:   "openai/gpt-4",
+		Choices: []api.Choice{{Message: api.Message{Role: "assistant", Content: message}}},
+	}
+
+	noColor := color.NoColor
+	defer func() { color.NoColor = noColor; noRender = false }()
+	color.NoColor = false
+
+	tests := []struct {
+		name     string
+		terminal bool
+		noRender bool
+		want     bool
+	}{
+		{"terminal", true, false, true},
+		{"not a terminal", false, false, false},
+		{"--no-render", true, true, false},
+	}
+	for _, tt := range tests {
+		noRender = tt.noRender
+		if got := renderMarkdownOn(tt.terminal); got != tt.want {
+			t.Errorf("%s: render = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// Rendering would drop the markup; plain output keeps it
+	for _, flag := range []bool{false, true} {
+		noRender = flag
+		got := captureStdout(t, func() {
+			if err := FormatChatResponse(resp, FormatPretty); err != nil {
+				t.Error(err)
+			}
+		})
+		if !strings.HasPrefix(got, message+"\n") {
+			t.Errorf("no-render %v: output = %q, want the message as written", flag, got)
+		}
+	}
+}
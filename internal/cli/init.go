@@ -0,0 +1,269 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+// initForce is the 'init --force' flag
+var initForce bool
+
+// maxKeyAttempts is how many times init asks for a key that the API rejects
+const maxKeyAttempts = 3
+
+// maxModelMatches is how many search results init shows at once
+const maxModelMatches = 10
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up the CLI interactively",
+	Long: `Set up OpenRouter CLI interactively.
+
+init asks for your API key (input is hidden), checks it with OpenRouter,
+then lets you search the model catalog for a default model and writes both
+to the user config file.
+
+Answers can also be piped in, one per line, for scripted setup:
+  printf '%s\n' "$OPENROUTER_KEY" claude 1 | openrouter init --force`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		wizard := &initWizard{
+			prompter: util.NewTerminalPrompter(),
+			out:      os.Stdout,
+			newClient: func(key string) *api.Client {
+				return api.NewClient(cfg.APIBaseURL, key, cfg.Timeout)
+			},
+		}
+		if err := wizard.Run(cfg); err != nil {
+			if errors.Is(err, io.EOF) {
+				err = fmt.Errorf("setup cancelled")
+			}
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Saved config to %s\n", config.GetConfigPath())
+		fmt.Println("Try it: openrouter chat \"Hello!\"")
+		return nil
+	},
+}
+
+// initWizard asks for the settings written by 'openrouter init'
+type initWizard struct {
+	prompter  util.Prompter
+	out       io.Writer
+	newClient func(key string) *api.Client
+}
+
+// Run fills in cfg's API key and default model from the user's answers
+func (w *initWizard) Run(cfg *config.Config) error {
+	if cfg.APIKeyBackend() != "" && !initForce {
+		overwrite, err := w.prompter.Confirm(
+			fmt.Sprintf("%s already has an API key. Replace it", config.GetConfigPath()), false)
+		if err != nil {
+			return err
+		}
+		if !overwrite {
+			return fmt.Errorf("setup cancelled (use --force to skip this question)")
+		}
+	}
+
+	key, client, err := w.askKey()
+	if err != nil {
+		return err
+	}
+
+	model, err := w.askModel(client, cfg.DefaultModel)
+	if err != nil {
+		return err
+	}
+
+	cfg.ClearAPIKey()
+	cfg.APIKey = key
+	cfg.DefaultModel = model
+	return nil
+}
+
+// askKey asks for an API key until the API accepts one
+func (w *initWizard) askKey() (string, *api.Client, error) {
+	fmt.Fprintln(w.out, "Get an API key at https://openrouter.ai/keys")
+	for attempt := 1; ; attempt++ {
+		key, err := w.prompter.AskSecret("OpenRouter API key")
+		if err != nil {
+			return "", nil, err
+		}
+		if key == "" {
+			fmt.Fprintln(w.out, "The API key is required.")
+			continue
+		}
+
+		client := w.newClient(key)
+		info, err := client.GetKeyInfo()
+		var apiErr *api.APIError
+		switch {
+		case err == nil:
+			fmt.Fprintf(w.out, "✓ Key accepted%s\n", describeKeyInfo(info))
+			return key, client, nil
+		case errors.As(err, &apiErr) && (apiErr.StatusCode == 401 || apiErr.StatusCode == 403):
+			fmt.Fprintf(w.out, "The key was rejected: %s\n", apiErr.Message)
+			if attempt == maxKeyAttempts {
+				return "", nil, fmt.Errorf("API key rejected %d times", maxKeyAttempts)
+			}
+		default:
+			// Offline or a custom api_base_url without the key endpoint
+			keep, cerr := w.prompter.Confirm(fmt.Sprintf("Could not check the key (%v). Use it anyway", err), false)
+			if cerr != nil {
+				return "", nil, cerr
+			}
+			if keep {
+				return key, client, nil
+			}
+		}
+	}
+}
+
+// askModel lets the user search the catalog for a default model
+// The current model is kept when the search is left empty or the catalog can't be fetched
+func (w *initWizard) askModel(client *api.Client, current string) (string, error) {
+	models, err := client.ListModels()
+	if err != nil {
+		fmt.Fprintf(w.out, "Could not fetch the model catalog: %v\n", err)
+		return w.prompter.Ask("Default model", current)
+	}
+
+	ids := make([]string, len(models))
+	for i, m := range models {
+		ids[i] = m.ID
+	}
+
+	query, err := w.prompter.Ask(fmt.Sprintf("Search models for a default (Enter keeps %s)", current), "")
+	for {
+		if err != nil {
+			return "", err
+		}
+		if query == "" {
+			return current, nil
+		}
+
+		matches := fuzzyFind(query, ids)
+		if len(matches) == 0 {
+			fmt.Fprintf(w.out, "No models match %q.\n", query)
+			query, err = w.prompter.Ask("Search again (Enter keeps "+current+")", "")
+			continue
+		}
+		if len(matches) > maxModelMatches {
+			matches = matches[:maxModelMatches]
+		}
+		for i, id := range matches {
+			fmt.Fprintf(w.out, "  %2d. %s\n", i+1, id)
+		}
+
+		answer, aerr := w.prompter.Ask("Pick a number, or type to search again", "1")
+		if aerr != nil {
+			return "", aerr
+		}
+		if n, nerr := strconv.Atoi(answer); nerr == nil {
+			if n >= 1 && n <= len(matches) {
+				return matches[n-1], nil
+			}
+			fmt.Fprintf(w.out, "Pick a number from 1 to %d.\n", len(matches))
+			continue
+		}
+		query = answer
+	}
+}
+
+// describeKeyInfo summarizes a key's label and credit for init
+func describeKeyInfo(info *api.KeyInfo) string {
+	var parts []string
+	if info.Label != "" {
+		parts = append(parts, info.Label)
+	}
+	if info.Limit != nil {
+		parts = append(parts, fmt.Sprintf("$%.2f of $%.2f used", info.Usage, *info.Limit))
+	} else {
+		parts = append(parts, fmt.Sprintf("$%.2f used", info.Usage))
+	}
+	if info.IsFreeTier {
+		parts = append(parts, "free tier")
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// fuzzyFind returns the candidates containing every character of query in
+// order, best matches first; spaces in the query are ignored
+func fuzzyFind(query string, candidates []string) []string {
+	query = strings.ToLower(strings.ReplaceAll(query, " ", ""))
+	type match struct {
+		id    string
+		score int
+	}
+	var matches []match
+	for _, c := range candidates {
+		if score, ok := fuzzyScore(query, strings.ToLower(c)); ok {
+			matches = append(matches, match{c, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return len(matches[i].id) < len(matches[j].id)
+	})
+
+	ids := make([]string, len(matches))
+	for i, m := range matches {
+		ids[i] = m.id
+	}
+	return ids
+}
+
+// fuzzyScore matches query as a subsequence of s
+// Consecutive characters and characters at the start of a word (after /, -,
+// ., : or space) score higher, so "gpt4o" ranks openai/gpt-4o above longer names
+func fuzzyScore(query, s string) (int, bool) {
+	score, qi, prev := 0, 0, -2
+	for si := 0; si < len(s) && qi < len(query); si++ {
+		if s[si] != query[qi] {
+			continue
+		}
+		score++
+		if si == prev+1 {
+			score += 2
+		}
+		if si == 0 || strings.ContainsRune("/-.: ", rune(s[si-1])) {
+			score += 3
+		}
+		prev = si
+		qi++
+	}
+	if qi < len(query) {
+		return 0, false
+	}
+	return score, true
+}
+
+func init() {
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Replace an existing API key without asking")
+}
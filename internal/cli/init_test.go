@@ -0,0 +1,105 @@
+package cli
+
+import (
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/mockserver"
+	"github.com/kdevrou/openrouter-cli/internal/util"
+)
+
+// newInitWizard returns a wizard that reads answers from input and talks to
+// a mock API accepting only the key sk-right
+func newInitWizard(t *testing.T, input string) (*initWizard, *strings.Builder) {
+	t.Helper()
+	mock, err := mockserver.New(mockserver.Options{APIKey: "sk-right"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+
+	out := &strings.Builder{}
+	return &initWizard{
+		prompter: util.NewLinePrompter(strings.NewReader(input), out),
+		out:      out,
+		newClient: func(key string) *api.Client {
+			client := api.NewClient(server.URL, key, 10)
+			client.Limiter = nil
+			return client
+		},
+	}, out
+}
+
+func TestInitWizard(t *testing.T) {
+	t.Setenv("OPENROUTER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	initForce = false
+
+	answers := []string{
+		"",         // empty key: asked again
+		"sk-wrong", // rejected by the API: asked again
+		"sk-right", // accepted
+		"zzqx",     // no matches: search again
+		"claude",   // one match
+		"9",        // out of range: the matches are shown again
+		"1",
+	}
+	wizard, out := newInitWizard(t, strings.Join(answers, "\n")+"\n")
+
+	cfg, err := config.LoadUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wizard.Run(cfg); err != nil {
+		t.Fatalf("Run: %v\n%s", err, out)
+	}
+	if err := config.Save(cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"The API key is required.",
+		"The key was rejected",
+		"✓ Key accepted",
+		`No models match "zzqx".`,
+		"Pick a number from 1 to 1.",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, out)
+		}
+	}
+
+	saved, err := config.LoadUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if saved.APIKey != "sk-right" {
+		t.Errorf("saved api_key = %q, want sk-right", saved.APIKey)
+	}
+	if saved.DefaultModel != "anthropic/claude-3.5-sonnet" {
+		t.Errorf("saved default_model = %q, want anthropic/claude-3.5-sonnet", saved.DefaultModel)
+	}
+}
+
+func TestInitWizardKeepsExistingKey(t *testing.T) {
+	t.Setenv("OPENROUTER_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+	initForce = false
+
+	// An unclear answer is asked again; "n" cancels
+	wizard, out := newInitWizard(t, "maybe\nn\n")
+	cfg := config.DefaultConfig()
+	cfg.APIKey = "sk-existing"
+	if err := wizard.Run(cfg); err == nil || !strings.Contains(err.Error(), "setup cancelled") {
+		t.Fatalf("Run error = %v, want setup cancelled", err)
+	}
+	if !strings.Contains(out.String(), "Please answer y or n.") {
+		t.Errorf("output doesn't re-prompt:\n%s", out)
+	}
+	if cfg.APIKey != "sk-existing" {
+		t.Errorf("api_key = %q, want it unchanged", cfg.APIKey)
+	}
+}
@@ -6,13 +6,16 @@ import (
 	"strings"
 
 	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// List command flags
-	filterName string
-	jsonList   bool
+	filterName  string
+	jsonList    bool
+	listColumns []string
+	noHeader    bool
 )
 
 var listCmd = &cobra.Command{
@@ -26,9 +29,22 @@ Use --filter to search for specific models:
   openrouter list --filter gpt
   openrouter list --filter claude
 
+Use --columns to choose which columns are shown:
+  openrouter list --columns id,name,context,prompt_price
+  openrouter list --columns id --no-header
+
+Available columns: id, name, context, prompt_price, completion_price,
+modality, created, tokenizer. Prices are shown in USD per 1M tokens.
+
 Use --json to get raw JSON output for scripting:
   openrouter list --json | jq '.[] | .id'
 
+Use -o/--output for other formats (csv, tsv, yaml, ndjson):
+  openrouter list -o csv --columns id,context,prompt_price > models.csv
+
+Use --format to render each model with a Go template:
+  openrouter list --format '{{.ID}}\t{{price .Pricing.Prompt}}'
+
 Manage unavailable models:
   openrouter config add-unavailable qwen/model:free
   openrouter config list-unavailable
@@ -40,8 +56,33 @@ Manage unavailable models:
 func runList(cmd *cobra.Command, args []string) error {
 	// Load configuration
 	cfg, err := GetConfig()
-	if err != nil {
+	if err == config.ErrNoAPIKey {
 		PrintSetupError()
+	} else if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	// Validate columns and output format before making any requests
+	columns, err := ParseModelColumns(listColumns)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	var shortcut OutputFormat
+	if jsonList {
+		shortcut = FormatJSON
+	}
+	format, err := ResolveOutputFormat(cfg, shortcut)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	tmpl, err := parseFormatFlag(shortcut, api.Model{})
+	if err != nil {
+		PrintError(err.Error())
+		return err
 	}
 
 	// Create API client
@@ -75,17 +116,10 @@ func runList(cmd *cobra.Command, args []string) error {
 		models = filtered
 	}
 
-	// Filter out unavailable models from config
-	unavailableModels := make(map[string]bool)
-	if cfg != nil && len(cfg.UnavailableModels) > 0 {
-		for _, m := range cfg.UnavailableModels {
-			unavailableModels[m] = true
-		}
-	}
-
+	// Filter out unavailable models from config, honoring globs and expiry
 	filtered := make([]api.Model, 0)
 	for _, m := range models {
-		if !unavailableModels[m.ID] {
+		if !cfg.IsModelUnavailable(m.ID) {
 			filtered = append(filtered, m)
 		}
 	}
@@ -95,22 +129,31 @@ func runList(cmd *cobra.Command, args []string) error {
 		PrintError("No models found")
 		if filterName != "" {
 			fmt.Fprintf(os.Stderr, "Try searching without filters or with different keywords\n")
-		} else if len(unavailableModels) > 0 {
+		} else if len(cfg.UnavailableModels) > 0 {
 			fmt.Fprintf(os.Stderr, "All models are marked as unavailable. Use 'openrouter config list-unavailable' to see them.\n")
 		}
 		return nil
 	}
 
 	// Format output
-	format := FormatPretty
-	if jsonList {
-		format = FormatJSON
+	if tmpl != nil {
+		for _, m := range models {
+			if err := executeTemplate(os.Stdout, tmpl, m); err != nil {
+				PrintError(err.Error())
+				return err
+			}
+		}
+		return nil
 	}
-
-	return FormatModelList(models, format)
+	return FormatModelList(models, format, ModelListOptions{
+		Columns:  columns,
+		NoHeader: noHeader,
+	})
 }
 
 func init() {
 	listCmd.Flags().StringVar(&filterName, "filter", "", "Filter models by name or ID")
 	listCmd.Flags().BoolVar(&jsonList, "json", false, "Output as JSON")
+	listCmd.Flags().StringSliceVar(&listColumns, "columns", nil, "Comma-separated columns to show ("+strings.Join(ModelColumnNames, ",")+")")
+	listCmd.Flags().BoolVar(&noHeader, "no-header", false, "Omit the table header")
 }
@@ -0,0 +1,38 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [file...]",
+	Short: "Rewrite config files in the current layout",
+	Long: `Rewrite config files written by older releases in the current layout.
+
+Files in an older layout still load, with a warning; this command updates
+them on disk. Comments are kept and the original is saved beside each file
+with a .bak suffix. With no arguments the user config file is migrated.`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		paths := args
+		if len(paths) == 0 {
+			paths = []string{config.GetConfigPath()}
+		}
+
+		for _, path := range paths {
+			notice, err := config.MigrateFile(path)
+			if err != nil {
+				PrintError(err.Error())
+				return err
+			}
+			if notice == "" {
+				fmt.Printf("%s is already current\n", path)
+				continue
+			}
+			fmt.Printf("✓ %s\n", notice)
+		}
+		return nil
+	},
+}
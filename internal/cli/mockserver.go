@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/mockserver"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Mock server command flags
+	mockPort       int
+	mockHost       string
+	mockResponse   string
+	mockScript     string
+	mockModels     string
+	mockRequireKey string
+	mockCredits    float64
+	mockLatency    time.Duration
+	mockChunkDelay time.Duration
+	mockQuiet      bool
+)
+
+var mockServerCmd = &cobra.Command{
+	Use:   "mock-server",
+	Short: "Run a local stand-in for the OpenRouter API",
+	Long: `Run a local server that answers like the OpenRouter API, for trying the CLI,
+scripts and tests offline. Point api_base_url at it:
+
+  OPENROUTER_API_BASE_URL=http://127.0.0.1:8080 openrouter chat "Hello"
+
+It serves /chat/completions, /models, /key and /credits, with or without the
+/api/v1 prefix. Chat requests are answered with the last user message
+echoed back, or with --response for every request. A request with
+"stream": true is answered with server-sent events, a word per event.
+
+Any API key is accepted unless --require-key is given. Requests for models
+not in the catalog fail with 400, as they do on OpenRouter. The catalog is
+a built-in list including openai/gpt-4, or --models with a saved /models
+response:
+
+  curl -s https://openrouter.ai/api/v1/models > models.json
+
+A --script file answers matching requests before --response or the echo.
+The first rule that matches the model and the last user message answers;
+a rule with times stops matching after that many replies, which is how
+errors are injected and then cleared:
+
+  responses:
+    - match: "(?i)weather"
+      content: "Sunny and 22°C."
+    - model: "anthropic/*"
+      content: "Hello from Claude."
+      delay: 2s
+    - match: "flaky"
+      status: 429
+      retry_after: 1
+      times: 1
+    - match: "outage"
+      status: 502
+      error: "Upstream provider unavailable"
+
+Examples:
+  openrouter mock-server
+  openrouter mock-server --port 9000 --response "OK"
+  openrouter mock-server --script responses.yaml --models models.json
+  openrouter mock-server --latency 300ms --chunk-delay 50ms`,
+	Args: cobra.NoArgs,
+	RunE: runMockServer,
+}
+
+func runMockServer(cmd *cobra.Command, args []string) error {
+	if mockPort < 0 || mockPort > 65535 {
+		PrintError("--port must be between 0 and 65535")
+		return fmt.Errorf("invalid flags")
+	}
+	opts := mockserver.Options{
+		Response:   mockResponse,
+		APIKey:     mockRequireKey,
+		Credits:    mockCredits,
+		Latency:    mockLatency,
+		ChunkDelay: mockChunkDelay,
+	}
+	if !mockQuiet {
+		opts.Log = os.Stderr
+	}
+	if mockScript != "" {
+		script, err := mockserver.LoadScript(mockScript)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		opts.Script = script
+	}
+	if mockModels != "" {
+		models, err := mockserver.LoadModels(mockModels)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		opts.Models = models
+	}
+	server, err := mockserver.New(opts)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(mockHost, strconv.Itoa(mockPort)))
+	if err != nil {
+		PrintError(fmt.Sprintf("failed to listen: %v", err))
+		return err
+	}
+	httpServer := &http.Server{Handler: server}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "✓ Mock OpenRouter API listening on http://%s\n", listener.Addr())
+	fmt.Fprintf(os.Stderr, "  Use it with: OPENROUTER_API_BASE_URL=http://%s openrouter ...\n", listener.Addr())
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		PrintError(err.Error())
+		return err
+	}
+	return nil
+}
+
+func init() {
+	mockServerCmd.Flags().IntVar(&mockPort, "port", 8080, "Port to listen on (0 picks a free port)")
+	mockServerCmd.Flags().StringVar(&mockHost, "host", "127.0.0.1", "Address to listen on")
+	mockServerCmd.Flags().StringVar(&mockResponse, "response", "", "Reply to every unscripted chat request (default: echo the prompt)")
+	mockServerCmd.Flags().StringVar(&mockScript, "script", "", "YAML file of scripted responses and errors")
+	mockServerCmd.Flags().StringVar(&mockModels, "models", "", "JSON file served for /models (default: a built-in catalog)")
+	mockServerCmd.Flags().StringVar(&mockRequireKey, "require-key", "", "Reject requests that don't use this API key")
+	mockServerCmd.Flags().Float64Var(&mockCredits, "credits", 10, "Total credits reported by /credits")
+	mockServerCmd.Flags().DurationVar(&mockLatency, "latency", 0, "Delay before every chat reply")
+	mockServerCmd.Flags().DurationVar(&mockChunkDelay, "chunk-delay", 0, "Delay between streamed chunks")
+	mockServerCmd.Flags().BoolVarP(&mockQuiet, "quiet", "q", false, "Don't log requests")
+}
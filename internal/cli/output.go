@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+// OutputFormatNames lists every supported output format
+var OutputFormatNames = []string{
+	string(FormatPretty), string(FormatRaw), string(FormatJSON),
+	string(FormatCSV), string(FormatTSV), string(FormatYAML), string(FormatNDJSON),
+}
+
+// ParseOutputFormat validates an output format name
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	for _, valid := range OutputFormatNames {
+		if name == valid {
+			return OutputFormat(name), nil
+		}
+	}
+	return "", fmt.Errorf("unknown output format %q (valid formats: %s)",
+		name, strings.Join(OutputFormatNames, ", "))
+}
+
+// ResolveOutputFormat picks the output format for a command
+// A command-specific shortcut such as --json or --raw wins over the global
+// --output flag, which wins over output_format from the config file
+func ResolveOutputFormat(cfg *config.Config, shortcut OutputFormat) (OutputFormat, error) {
+	if shortcut != "" {
+		return shortcut, nil
+	}
+	if outputFormat != "" {
+		return ParseOutputFormat(outputFormat)
+	}
+	if cfg != nil && cfg.OutputFormat != "" {
+		format, err := ParseOutputFormat(cfg.OutputFormat)
+		if err != nil {
+			return "", fmt.Errorf("invalid output_format in config: %w", err)
+		}
+		return format, nil
+	}
+	return FormatPretty, nil
+}
+
+// writeDelimited writes rows as CSV (sep ',') or TSV (sep '\t')
+// TSV fields cannot be quoted, so tabs, newlines and backslashes are escaped instead
+func writeDelimited(w io.Writer, headers []string, rows [][]string, sep rune) error {
+	if sep == '\t' {
+		escaper := strings.NewReplacer(`\`, `\\`, "\t", `\t`, "\n", `\n`, "\r", `\r`)
+		var sb strings.Builder
+		writeRow := func(cells []string) {
+			for i, cell := range cells {
+				if i > 0 {
+					sb.WriteByte('\t')
+				}
+				sb.WriteString(escaper.Replace(cell))
+			}
+			sb.WriteByte('\n')
+		}
+		if headers != nil {
+			writeRow(headers)
+		}
+		for _, row := range rows {
+			writeRow(row)
+		}
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = sep
+	if headers != nil {
+		if err := cw.Write(headers); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+	}
+	if err := cw.WriteAll(rows); err != nil {
+		return fmt.Errorf("failed to write rows: %w", err)
+	}
+	return nil
+}
+
+// writeNDJSON writes each item as a single line of JSON
+func writeNDJSON[T any](w io.Writer, items []T) error {
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	for _, item := range items {
+		if err := enc.Encode(item); err != nil {
+			return fmt.Errorf("failed to encode item: %w", err)
+		}
+	}
+	return nil
+}
+
+// writeYAML writes v as YAML using its JSON field names
+// The API types only carry json tags, so v is round-tripped through JSON
+// into a yaml.Node, which keeps the field order of the original struct
+func writeYAML(w io.Writer, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal value: %w", err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return fmt.Errorf("failed to convert to YAML: %w", err)
+	}
+	clearYAMLStyle(&node)
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return enc.Close()
+}
+
+// clearYAMLStyle resets the flow and quoting style inherited from the JSON source
+func clearYAMLStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearYAMLStyle(child)
+	}
+}
@@ -0,0 +1,221 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Profile create flags
+	profileKey     string
+	profileBaseURL string
+	profileModel   string
+	profileTimeout int
+)
+
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "Manage named configuration profiles",
+	Long: `Manage named profiles for multiple accounts and environments.
+
+A profile overrides api_key, api_base_url, default_model, timeout and
+routing from the top-level config. The active profile is chosen by
+--profile, then $OPENROUTER_PROFILE, then current_profile in the config file.
+
+Examples:
+  openrouter config profile create team --key sk-or-v1-... --model anthropic/claude-3.5-sonnet
+  openrouter config profile create local --base-url http://localhost:8080
+  openrouter config profile use team
+  openrouter --profile local chat "Hello"
+  openrouter config profile copy team team-eu
+  openrouter config profile delete local`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List profiles",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadFiles()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		names := cfg.ProfileNames()
+		if len(names) == 0 {
+			fmt.Println("No profiles configured.")
+			return nil
+		}
+
+		active, _ := config.SelectedProfile(cfg)
+		for _, name := range names {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			fmt.Printf("%s %s%s\n", marker, name, describeProfile(cfg.Profiles[name]))
+		}
+		return nil
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use <name>",
+	Short: "Set the current profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		name := args[0]
+		if err := cfg.UseProfile(name); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Now using profile %s\n", name)
+		return nil
+	},
+}
+
+var profileCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		profile := &config.Profile{}
+		if cmd.Flags().Changed("key") {
+			profile.APIKey = &profileKey
+		}
+		if cmd.Flags().Changed("base-url") {
+			profile.APIBaseURL = &profileBaseURL
+		}
+		if cmd.Flags().Changed("model") {
+			profile.DefaultModel = &profileModel
+		}
+		if cmd.Flags().Changed("timeout") {
+			if profileTimeout <= 0 {
+				PrintError("timeout must be a positive number of seconds")
+				return fmt.Errorf("invalid timeout")
+			}
+			profile.Timeout = &profileTimeout
+		}
+
+		name := args[0]
+		if err := cfg.AddProfile(name, profile); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Created profile %s\n", name)
+		return nil
+	},
+}
+
+var profileDeleteCmd = &cobra.Command{
+	Use:   "delete <name>",
+	Short: "Delete a profile",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		name := args[0]
+		if err := cfg.DeleteProfile(name); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Deleted profile %s\n", name)
+		return nil
+	},
+}
+
+var profileCopyCmd = &cobra.Command{
+	Use:   "copy <source> <destination>",
+	Short: "Copy a profile",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := cfg.CopyProfile(args[0], args[1]); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Copied profile %s to %s\n", args[0], args[1])
+		return nil
+	},
+}
+
+// describeProfile summarizes the overrides in a profile for 'profile list'
+func describeProfile(p *config.Profile) string {
+	var desc string
+	if p.DefaultModel != nil {
+		desc += "  model=" + *p.DefaultModel
+	}
+	if p.APIBaseURL != nil {
+		desc += "  url=" + *p.APIBaseURL
+	}
+	if p.APIKey != nil {
+		desc += "  key=" + maskAPIKey(*p.APIKey)
+	}
+	if p.Timeout != nil {
+		desc += fmt.Sprintf("  timeout=%ds", *p.Timeout)
+	}
+	if p.Routing != nil {
+		desc += "  routing"
+	}
+	return desc
+}
+
+func init() {
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	profileCmd.AddCommand(profileCreateCmd)
+	profileCmd.AddCommand(profileDeleteCmd)
+	profileCmd.AddCommand(profileCopyCmd)
+
+	profileCreateCmd.Flags().StringVar(&profileKey, "key", "", "API key for this profile")
+	profileCreateCmd.Flags().StringVar(&profileBaseURL, "base-url", "", "API base URL for this profile")
+	profileCreateCmd.Flags().StringVar(&profileModel, "model", "", "Default model for this profile")
+	profileCreateCmd.Flags().IntVar(&profileTimeout, "timeout", 0, "Request timeout in seconds for this profile")
+}
@@ -1,17 +1,30 @@
 package cli
 
 import (
+	"fmt"
 	"os"
+	"strings"
 
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/cassette"
 	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/util"
 	"github.com/spf13/cobra"
 )
 
 var (
 	// Global flags
-	configPath string
-	apiKey     string
-	debug      bool
+	configPath     string
+	apiKey         string
+	debug          bool
+	outputFormat   string
+	formatTemplate string
+	profileName    string
+	noCache        bool
+	cacheOnly      bool
+	recordDir      string
+	replayDir      string
 )
 
 // RootCmd is the root command
@@ -24,12 +37,20 @@ It allows you to:
 - Send chat completions to 400+ AI models
 - List available models with pricing and capabilities
 - Pipe text input and output for integration with other tools
+- Reuse prompts with templates and variables
 
 Get started:
+  openrouter init
   openrouter chat "Hello, world!"
   openrouter list
   echo "Tell me a joke" | openrouter chat`,
 	Version: "0.1.0",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		// Point config loading at --config before any subcommand runs
+		config.SetConfigPath(configPath)
+		config.SetProfile(profileName)
+		return setupCassettes()
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Show help if no subcommand
 		cmd.Help()
@@ -43,18 +64,76 @@ func Execute() error {
 
 func init() {
 	// Global flags
-	RootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to config file")
+	RootCmd.PersistentFlags().StringVar(&configPath, "config", "", "Path to config file (overrides $OPENROUTER_CONFIG)")
 	RootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "OpenRouter API key (overrides config)")
+	RootCmd.PersistentFlags().StringVar(&formatTemplate, "format", "", "Format output using a Go template (e.g. '{{.Model}}')")
+	RootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "Config profile to use (overrides $OPENROUTER_PROFILE)")
 	RootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug output")
+	RootCmd.PersistentFlags().BoolVar(&noCache, "no-cache", false, "Don't use the response cache for this run")
+	RootCmd.PersistentFlags().BoolVar(&cacheOnly, "cache-only", false, "Answer only from the response cache; fail requests that aren't cached")
+	RootCmd.PersistentFlags().StringVar(&recordDir, "record", "", "Record every API request and response to cassettes in this directory")
+	RootCmd.PersistentFlags().StringVar(&replayDir, "replay", "", "Answer API requests from cassettes in this directory instead of the network")
+	RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "", "Output format: "+strings.Join(OutputFormatNames, ", ")+" (overrides config)")
 
 	// Register subcommands
+	RootCmd.AddCommand(initCmd)
 	RootCmd.AddCommand(chatCmd)
 	RootCmd.AddCommand(listCmd)
 	RootCmd.AddCommand(configCmd)
+	RootCmd.AddCommand(aliasCmd)
+	RootCmd.AddCommand(templatesCmd)
+	RootCmd.AddCommand(batchCmd)
+	RootCmd.AddCommand(compareCmd)
+	RootCmd.AddCommand(evalCmd)
+	RootCmd.AddCommand(cacheCmd)
+	RootCmd.AddCommand(mockServerCmd)
+	RootCmd.AddCommand(serveCmd)
+}
+
+// setupCassettes makes every API client record to --record or replay from
+// --replay
+func setupCassettes() error {
+	switch {
+	case recordDir != "" && replayDir != "":
+		PrintError("--record and --replay can't be used together")
+		return fmt.Errorf("invalid flags")
+	case recordDir != "":
+		recorder, err := cassette.NewRecorder(recordDir)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		api.SetDefaultTransport(recorder)
+	case replayDir != "":
+		replayer, err := cassette.NewReplayer(replayDir)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		api.SetDefaultTransport(replayer)
+	}
+	return nil
 }
 
 // GetConfig loads the configuration with command-line overrides
 func GetConfig() (*config.Config, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	printConfigWarnings(cfg)
+
+	// Fetch the key from its secret backend and validate it is set
+	if err := cfg.ResolveAPIKey(promptPassphrase); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// loadConfig loads the configuration with command-line overrides, without
+// resolving the API key
+func loadConfig() (*config.Config, error) {
 	cfg, err := config.Load()
 	if err != nil {
 		return nil, err
@@ -62,15 +141,33 @@ func GetConfig() (*config.Config, error) {
 
 	// Override with command-line flags
 	if apiKey != "" {
+		cfg.ClearAPIKey()
 		cfg.APIKey = apiKey
+		cfg.SetOrigin("api_key", "flag: --api-key")
 	}
 
-	// Validate API key is set
-	if cfg.APIKey == "" {
-		return nil, config.ErrNoAPIKey
+	// Every client in this process shares the configured limits
+	api.DefaultLimiter().SetLimits(cfg.RequestsPerMinute, cfg.TokensPerMinute)
+	return cfg, nil
+}
+
+// printConfigWarnings reports problems found while loading config files
+func printConfigWarnings(cfg *config.Config) {
+	for _, warning := range cfg.Warnings {
+		fmt.Fprintf(os.Stderr, "%s %s\n", color.YellowString("Warning:"), warning)
 	}
+}
 
-	return cfg, nil
+// promptPassphrase returns $OPENROUTER_PASSPHRASE or asks for the passphrase on the terminal
+func promptPassphrase() (string, error) {
+	if pass := os.Getenv("OPENROUTER_PASSPHRASE"); pass != "" {
+		return pass, nil
+	}
+	pass, err := util.ReadPassword("Passphrase for API key: ")
+	if err == util.ErrNoTerminal {
+		return "", fmt.Errorf("API key is encrypted: set OPENROUTER_PASSPHRASE or run from a terminal")
+	}
+	return pass, err
 }
 
 // PrintSetupError prints an error message when API key is not configured
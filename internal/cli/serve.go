@@ -0,0 +1,273 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/proxy"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Serve command flags
+	servePort         int
+	serveHost         string
+	serveToken        string
+	serveLog          string
+	serveQuiet        bool
+	serveMaxCost      float64
+	serveMaxDailyCost float64
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run a local OpenAI-compatible proxy to OpenRouter",
+	Long: `Serve an OpenAI-compatible API on this machine that forwards to OpenRouter
+with your configured key, so editors, notebooks and SDKs that speak the
+OpenAI API can use OpenRouter without being given the key.
+
+Endpoints:
+  POST /v1/chat/completions
+  GET  /v1/models            (OpenRouter's models and your aliases)
+
+Callers authenticate with a local bearer token, not your OpenRouter key.
+Set it with --token or $OPENROUTER_SERVE_TOKEN; otherwise a random token is
+made up and printed at startup. The server listens on 127.0.0.1 unless
+--host says otherwise.
+
+Requests get the same treatment as 'openrouter chat': model aliases are
+resolved, a missing model, temperature or max_tokens comes from the config
+defaults, and provider routing is added. Requests using only the fields the
+CLI knows are answered from the response cache when it is on. Requests
+with other fields (tools, stop, top_p...) are forwarded as they are, and
+"stream": true responses are passed through as they arrive.
+
+Each request is logged to stderr with its tokens and cost; --log also
+appends it to a JSONL file.
+
+--max-cost and --max-daily-cost cap the dollars spent since the server
+started and since midnight. Once a cap is reached, requests are refused
+with a 402 insufficient_quota error. Spending logged to the --log file
+earlier today counts toward the daily cap. Only requests whose price is
+known count, and requests already running are allowed to finish.
+
+Examples:
+  openrouter serve
+  openrouter serve --port 9000 --token my-local-token --log usage.jsonl
+  openrouter serve --log usage.jsonl --max-daily-cost 5
+
+  OPENAI_BASE_URL=http://127.0.0.1:8787/v1 OPENAI_API_KEY=my-local-token my-tool`,
+	Args: cobra.NoArgs,
+	RunE: runServe,
+}
+
+func runServe(cmd *cobra.Command, args []string) error {
+	cfg, err := GetConfig()
+	if err == config.ErrNoAPIKey {
+		PrintSetupError()
+		return err
+	} else if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	if servePort < 0 || servePort > 65535 {
+		PrintError("--port must be between 0 and 65535")
+		return fmt.Errorf("invalid flags")
+	}
+	if serveMaxCost < 0 || serveMaxDailyCost < 0 {
+		PrintError("--max-cost and --max-daily-cost can't be negative")
+		return fmt.Errorf("invalid flags")
+	}
+	token := serveToken
+	if token == "" {
+		token = os.Getenv("OPENROUTER_SERVE_TOKEN")
+	}
+	generated := token == ""
+	if generated {
+		if token, err = newServeToken(); err != nil {
+			PrintError(fmt.Sprintf("failed to generate a token: %v", err))
+			return err
+		}
+	}
+
+	client, err := newChatClient(cfg)
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	logEntry, closeLog, err := serveLogger()
+	if err != nil {
+		PrintError(err.Error())
+		return err
+	}
+	defer closeLog()
+
+	server := &proxy.Server{
+		Client: client,
+		Token:  token,
+		Defaults: proxy.Defaults{
+			Model:       cfg.DefaultModel,
+			Temperature: cfg.DefaultTemp,
+			MaxTokens:   cfg.DefaultMaxTokens,
+			Provider:    providerPreferences(cfg.Routing),
+		},
+		Resolve: cfg.ResolveModel,
+		Aliases: cfg.AliasNames(),
+		Cost:    catalogPricer(client),
+		Budget:  proxy.Budget{PerRun: serveMaxCost, PerDay: serveMaxDailyCost},
+		Log:     logEntry,
+	}
+	if serveLog != "" && serveMaxDailyCost > 0 {
+		if err := addLoggedSpend(server, serveLog); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+	}
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(serveHost, strconv.Itoa(servePort)))
+	if err != nil {
+		PrintError(fmt.Sprintf("failed to listen: %v", err))
+		return err
+	}
+	httpServer := &http.Server{Handler: server}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		httpServer.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(os.Stderr, "✓ OpenAI-compatible API listening on http://%s/v1\n", listener.Addr())
+	if generated {
+		fmt.Fprintf(os.Stderr, "  Token: %s\n", token)
+	}
+	fmt.Fprintf(os.Stderr, "  Use it with: OPENAI_BASE_URL=http://%s/v1 OPENAI_API_KEY=<token>\n", listener.Addr())
+	if serveMaxCost > 0 {
+		fmt.Fprintf(os.Stderr, "  Spending cap: $%g for this run\n", serveMaxCost)
+	}
+	if serveMaxDailyCost > 0 {
+		fmt.Fprintf(os.Stderr, "  Spending cap: $%g a day\n", serveMaxDailyCost)
+	}
+	if err := httpServer.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		PrintError(err.Error())
+		return err
+	}
+	return nil
+}
+
+// newServeToken makes up a random local token
+func newServeToken() (string, error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "sk-local-" + hex.EncodeToString(b), nil
+}
+
+// serveLogger returns a function that prints each request to stderr and,
+// with --log, appends it to that file
+func serveLogger() (func(proxy.Entry), func(), error) {
+	var mu sync.Mutex
+	var file *os.File
+	if serveLog != "" {
+		f, err := os.OpenFile(serveLog, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		file = f
+	}
+	closeLog := func() {
+		if file != nil {
+			file.Close()
+		}
+	}
+
+	return func(e proxy.Entry) {
+		mu.Lock()
+		defer mu.Unlock()
+		if !serveQuiet {
+			fmt.Fprintln(os.Stderr, describeServeEntry(e))
+		}
+		if file != nil {
+			data, _ := json.Marshal(e)
+			if _, err := file.Write(append(data, '\n')); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to write log: %v\n", err)
+			}
+		}
+	}, closeLog, nil
+}
+
+// addLoggedSpend counts the costs in an earlier --log file toward the
+// daily cap; a missing file counts nothing
+func addLoggedSpend(server *proxy.Server, path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var e proxy.Entry
+		if json.Unmarshal(scanner.Bytes(), &e) == nil && e.Cost != nil {
+			server.AddSpend(e.Time, *e.Cost, false)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read log file: %w", err)
+	}
+	return nil
+}
+
+// describeServeEntry formats a request for the stderr log, e.g.
+// "15:04:05 200 openai/gpt-4o 120 tokens $0.000420 1.2s"
+func describeServeEntry(e proxy.Entry) string {
+	line := fmt.Sprintf("%s %d %s", e.Time.Local().Format(time.TimeOnly), e.Status, e.Model)
+	if e.Stream {
+		line += " (stream)"
+	}
+	if e.Usage != nil {
+		line += fmt.Sprintf(" %d tokens", e.Usage.TotalTokens)
+	}
+	if e.Cost != nil {
+		line += fmt.Sprintf(" $%.6f", *e.Cost)
+	}
+	if e.Cached {
+		line += " cached"
+	}
+	line += " " + seconds(e.DurationMS)
+	if e.Error != "" {
+		line += " - " + e.Error
+	}
+	return line
+}
+
+func init() {
+	serveCmd.Flags().IntVar(&servePort, "port", 8787, "Port to listen on (0 picks a free port)")
+	serveCmd.Flags().StringVar(&serveHost, "host", "127.0.0.1", "Address to listen on")
+	serveCmd.Flags().StringVar(&serveToken, "token", "", "Bearer token callers must send (default: $OPENROUTER_SERVE_TOKEN, or a random one)")
+	serveCmd.Flags().StringVar(&serveLog, "log", "", "Append a JSON line per request to this file")
+	serveCmd.Flags().BoolVarP(&serveQuiet, "quiet", "q", false, "Don't log requests to stderr")
+	serveCmd.Flags().Float64Var(&serveMaxCost, "max-cost", 0, "Refuse requests once this many dollars are spent (0 for no cap)")
+	serveCmd.Flags().Float64Var(&serveMaxDailyCost, "max-daily-cost", 0, "Refuse requests once this many dollars are spent today, counting --log (0 for no cap)")
+}
@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/util"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// set-key flags
+	keyBackend string
+	keyFile    string
+)
+
+var setKeyCmd = &cobra.Command{
+	Use:   "set-key",
+	Short: "Store the API key in a secret backend",
+	Long: `Store your OpenRouter API key without echoing it.
+
+The key is read from a hidden prompt, or from stdin when piped:
+  openrouter config set-key
+  pass show openrouter | openrouter config set-key --backend encrypted
+
+Backends:
+  plain      Store api_key in the config file (default)
+  file       Write the key to a file (0600) and set api_key_file
+  encrypted  Encrypt the key with a passphrase and store it as api_key_encrypted
+
+With a selected profile (--profile, $OPENROUTER_PROFILE or current_profile)
+the key is stored in that profile instead of at the top level.
+
+The passphrase for the encrypted backend is read from $OPENROUTER_PASSPHRASE
+or prompted for; it is asked for again whenever the key is needed.
+
+To fetch the key from a password manager instead, set a command:
+  openrouter config set api_key_command "pass show openrouter"`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := config.LoadUser()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		if keyBackend == config.BackendCommand {
+			PrintError("the command backend is read-only; use 'openrouter config set api_key_command <command>'")
+			return fmt.Errorf("unsupported backend")
+		}
+		if keyBackend != config.BackendPlain && keyBackend != config.BackendFile && keyBackend != config.BackendEncrypted {
+			PrintError(fmt.Sprintf("unknown backend %q (valid backends: plain, file, encrypted)", keyBackend))
+			return fmt.Errorf("unknown backend")
+		}
+
+		// The key goes to the selected profile, if any, so it is the one used
+		profile, _ := config.SelectedProfile(cfg)
+		if _, ok := cfg.Profiles[profile]; profile != "" && !ok {
+			PrintError(fmt.Sprintf("profile %s not found in %s", profile, config.GetConfigPath()))
+			return fmt.Errorf("profile not found")
+		}
+
+		key, err := readSecret("API key: ")
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		if key == "" {
+			PrintError("API key cannot be empty")
+			return fmt.Errorf("empty key")
+		}
+
+		// Prepare the new value before touching the config so a failure leaves it intact
+		var value, location string
+		switch keyBackend {
+		case config.BackendPlain:
+			value = key
+			location = config.GetConfigPath()
+		case config.BackendFile:
+			path := keyFile
+			if path == "" {
+				path = config.DefaultKeyFilePath()
+			}
+			if err := config.WriteKeyFile(path, key); err != nil {
+				PrintError(err.Error())
+				return err
+			}
+			value = path
+			location = path
+		case config.BackendEncrypted:
+			pass, err := newPassphrase()
+			if err != nil {
+				PrintError(err.Error())
+				return err
+			}
+			sealed, err := config.EncryptSecret(key, pass)
+			if err != nil {
+				PrintError(err.Error())
+				return err
+			}
+			value = sealed
+			location = config.GetConfigPath() + " (encrypted)"
+		}
+		if profile != "" {
+			location += fmt.Sprintf(" for profile %s", profile)
+		}
+
+		if err := cfg.SetAPIKey(profile, keyBackend, value); err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		if err := config.Save(cfg); err != nil {
+			PrintError(fmt.Sprintf("failed to save config: %v", err))
+			return err
+		}
+
+		fmt.Printf("✓ Stored API key %s in %s\n", maskAPIKey(key), location)
+		return nil
+	},
+}
+
+// readSecret reads a secret from a hidden prompt, or from stdin when it is piped
+func readSecret(prompt string) (string, error) {
+	if !util.IsTerminal(os.Stdin) {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read from stdin: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	secret, err := util.ReadPassword(prompt)
+	return strings.TrimSpace(secret), err
+}
+
+// newPassphrase returns $OPENROUTER_PASSPHRASE or prompts twice for a new passphrase
+func newPassphrase() (string, error) {
+	if pass := os.Getenv("OPENROUTER_PASSPHRASE"); pass != "" {
+		return pass, nil
+	}
+
+	pass, err := util.ReadPassword("New passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if pass == "" {
+		return "", fmt.Errorf("passphrase cannot be empty")
+	}
+	confirm, err := util.ReadPassword("Repeat passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if pass != confirm {
+		return "", fmt.Errorf("passphrases do not match")
+	}
+	return pass, nil
+}
+
+// describeAPIKey returns how the API key is shown by 'config show' and 'config get',
+// along with the config key that supplies it
+func describeAPIKey(cfg *config.Config) (string, string) {
+	switch cfg.APIKeyBackend() {
+	case config.BackendCommand:
+		return fmt.Sprintf("(from command: %s)", cfg.APIKeyCommand), "api_key_command"
+	case config.BackendFile:
+		return fmt.Sprintf("(from file: %s)", cfg.APIKeyFile), "api_key_file"
+	case config.BackendEncrypted:
+		return "(encrypted)", "api_key_encrypted"
+	}
+	return maskAPIKey(cfg.APIKey), "api_key"
+}
+
+func init() {
+	setKeyCmd.Flags().StringVar(&keyBackend, "backend", config.BackendPlain, "Where to store the key: plain, file or encrypted")
+	setKeyCmd.Flags().StringVar(&keyFile, "file", "", "Key file path for the file backend (default: next to the config file)")
+}
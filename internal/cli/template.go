@@ -0,0 +1,91 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// templateFuncs are the helper functions available to --format templates
+var templateFuncs = template.FuncMap{
+	// json renders a value as compact JSON: {{json .Usage}}
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	},
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+	// price converts a per-token price into dollars per 1M tokens: {{price .Pricing.Prompt}}
+	"price": formatPricePerMillion,
+	// truncate shortens a string to n characters: {{truncate 20 .Name}} or {{.Name | truncate 20}}
+	"truncate": func(n int, s string) string { return truncate(s, n) },
+	// wrap word-wraps text at n columns: {{wrap 80 (index .Choices 0).Message.Content}}
+	"wrap": func(n int, s string) string { return WordWrap(s, n) },
+}
+
+// templateEscapes turns escape sequences typed in a shell into real characters
+var templateEscapes = strings.NewReplacer(`\t`, "\t", `\n`, "\n")
+
+// ParseFormatTemplate compiles a --format template
+// Chat templates are evaluated against api.ChatCompletionResponse,
+// list templates against each api.Model
+func ParseFormatTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("format").
+		Funcs(templateFuncs).
+		Option("missingkey=error").
+		Parse(templateEscapes.Replace(text))
+	if err != nil {
+		return nil, fmt.Errorf("invalid --format template: %w", err)
+	}
+	return tmpl, nil
+}
+
+// executeTemplate renders data with tmpl, followed by a newline
+func executeTemplate(w io.Writer, tmpl *template.Template, data interface{}) error {
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return fmt.Errorf("failed to execute --format template: %w", err)
+	}
+	sb.WriteByte('\n')
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// parseFormatFlag compiles the global --format flag, if set, and tries it on
+// sample, the zero value of the type it will render
+// It rejects combining --format with --output or a shortcut such as --json
+func parseFormatFlag(shortcut OutputFormat, sample interface{}) (*template.Template, error) {
+	if formatTemplate == "" {
+		return nil, nil
+	}
+	if outputFormat != "" {
+		return nil, fmt.Errorf("--format cannot be combined with --output")
+	}
+	if shortcut != "" {
+		return nil, fmt.Errorf("--format cannot be combined with --%s", shortcut)
+	}
+	tmpl, err := ParseFormatTemplate(formatTemplate)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkFormatTemplate(tmpl, sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// checkFormatTemplate runs tmpl against sample so a misspelt field such as
+// {{.Modle}} fails before any request is sent. Errors that depend on the
+// data, like indexing an empty list, are left for the real output.
+func checkFormatTemplate(tmpl *template.Template, sample interface{}) error {
+	err := tmpl.Execute(io.Discard, sample)
+	if err != nil && strings.Contains(err.Error(), "can't evaluate field") {
+		return fmt.Errorf("invalid --format template: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package cli
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+func TestParseFormatFlag(t *testing.T) {
+	defer func() { formatTemplate = "" }()
+
+	tests := []struct {
+		format string
+		sample interface{}
+		want   string // error substring, or "" if the template is accepted
+	}{
+		{`{{.ID}}\t{{price .Pricing.Prompt}}`, api.Model{}, ""},
+		{`{{.Architecture.Modality | upper}}`, api.Model{}, ""},
+		{`{{.Foo}}`, api.Model{}, "can't evaluate field Foo"},
+		{`{{.Pricing.Input}}`, api.Model{}, "can't evaluate field Input"},
+		{`{{.ID`, api.Model{}, "invalid --format template"},
+		// Indexing the empty Choices of the sample only fails on real data
+		{`{{.Model}} {{(index .Choices 0).Message.Content}}`, api.ChatCompletionResponse{}, ""},
+		{`{{.Modle}}`, api.ChatCompletionResponse{}, "can't evaluate field Modle"},
+	}
+	for _, tt := range tests {
+		formatTemplate = tt.format
+		tmpl, err := parseFormatFlag("", tt.sample)
+		switch {
+		case tt.want == "" && (err != nil || tmpl == nil):
+			t.Errorf("%s: %v", tt.format, err)
+		case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+			t.Errorf("%s: err = %v, want %q", tt.format, err, tt.want)
+		}
+	}
+}
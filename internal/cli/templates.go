@@ -0,0 +1,262 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/kdevrou/openrouter-cli/internal/config"
+	"github.com/kdevrou/openrouter-cli/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+var (
+	// Templates new flags
+	templateProject bool
+	templateNoEdit  bool
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "Manage prompt templates",
+	Long: `Manage reusable prompt templates for 'openrouter chat --template'.
+
+A template is a ` + templates.Ext + ` file with YAML front-matter and a Go template body:
+
+  ---
+  description: Review a diff
+  model: anthropic/claude-3.5-sonnet
+  temperature: 0.2
+  system: You are a careful reviewer of {{.lang}} code.
+  vars: [lang]
+  defaults:
+    focus: correctness
+  ---
+  Review this diff, focusing on {{.focus}}:
+
+  {{.input}}
+
+Variables are set with --var name=value. {{.input}} is the prompt argument
+and piped stdin. Variables listed in vars, or used without a default, are
+required; missing ones are reported before any request is sent.
+
+Templates are looked up in .openrouter/templates directories from the
+working directory upwards, then in templates_dir (by default the templates
+directory beside the config file). A project template replaces a user
+template with the same name.
+
+Examples:
+  openrouter templates new review
+  openrouter templates list
+  openrouter templates show review
+  openrouter chat --template review --var lang=go < diff.patch`,
+}
+
+var templatesListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List available templates",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		printConfigWarnings(cfg)
+
+		entries := templates.List(cfg.TemplateDirs())
+		if len(entries) == 0 {
+			fmt.Printf("No templates found. Create one with 'openrouter templates new <name>' (stored in %s).\n", cfg.UserTemplatesDir())
+			return nil
+		}
+
+		width := 0
+		for _, e := range entries {
+			width = max(width, len(e.Name))
+		}
+		for _, e := range entries {
+			switch {
+			case e.Err != nil:
+				fmt.Printf("%-*s  %s\n", width, e.Name, color.RedString("invalid: %v", e.Err))
+			case e.Shadowed:
+				fmt.Printf("%-*s  %s\n", width, e.Name, "(overridden) "+e.Path)
+			default:
+				fmt.Printf("%-*s  %s%s\n", width, e.Name, e.Template.Description, describeVars(e.Template))
+			}
+		}
+		return nil
+	},
+}
+
+var templatesShowCmd = &cobra.Command{
+	Use:               "show <name>",
+	Short:             "Show a template's file and variables",
+	Args:              cobra.ExactArgs(1),
+	ValidArgsFunction: completeTemplates,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		t, err := templates.Find(cfg.TemplateDirs(), args[0])
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+		data, err := os.ReadFile(t.Path)
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		fmt.Printf("# %s\n", t.Path)
+		if required := t.Required(); len(required) > 0 {
+			fmt.Printf("# Required: %s\n", strings.Join(required, ", "))
+		}
+		fmt.Println()
+		fmt.Print(string(data))
+		return nil
+	},
+}
+
+var templatesNewCmd = &cobra.Command{
+	Use:   "new <name>",
+	Short: "Create a template and open it in $EDITOR",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadConfig()
+		if err != nil {
+			PrintError(err.Error())
+			return err
+		}
+
+		name := args[0]
+		if name == "" || strings.ContainsAny(name, `/\ `) {
+			err := fmt.Errorf("invalid template name %q", name)
+			PrintError(err.Error())
+			return err
+		}
+
+		dir := cfg.UserTemplatesDir()
+		if templateProject {
+			dir = filepath.FromSlash(config.ProjectTemplatesDir)
+		}
+		path := filepath.Join(dir, name+templates.Ext)
+		if _, err := os.Stat(path); err == nil {
+			err := fmt.Errorf("%s already exists", path)
+			PrintError(err.Error())
+			return err
+		}
+
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			PrintError(fmt.Sprintf("failed to create directory: %v", err))
+			return err
+		}
+		if err := os.WriteFile(path, []byte(templates.Skeleton(name)), 0644); err != nil {
+			PrintError(fmt.Sprintf("failed to write template: %v", err))
+			return err
+		}
+
+		if !templateNoEdit {
+			if err := runEditor(path); err != nil {
+				PrintError(err.Error())
+				return err
+			}
+		}
+
+		fmt.Printf("✓ Created template %s at %s\n", name, path)
+		data, err := os.ReadFile(path)
+		if err == nil {
+			_, err = templates.Parse(name, path, data)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		}
+		return nil
+	},
+}
+
+// loadPromptTemplate finds the named template and renders it with the
+// --var values and input, which is appended when the template doesn't use it
+func loadPromptTemplate(cfg *config.Config, name string, assignments []string, input string) (prompt, system string, t *templates.Template, err error) {
+	t, err = templates.Find(cfg.TemplateDirs(), name)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	vars, err := parseVars(assignments)
+	if err != nil {
+		return "", "", nil, err
+	}
+	usesInput := false
+	for _, v := range t.Variables() {
+		usesInput = usesInput || v == templates.InputVar
+	}
+	if _, ok := vars[templates.InputVar]; !ok && input != "" {
+		vars[templates.InputVar] = input
+	}
+
+	prompt, system, err = t.Render(vars)
+	if err != nil {
+		return "", "", nil, err
+	}
+	if !usesInput && input != "" {
+		prompt += "\n\n" + input
+	}
+	return prompt, system, t, nil
+}
+
+// parseVars parses --var name=value assignments
+func parseVars(assignments []string) (map[string]string, error) {
+	vars := make(map[string]string, len(assignments))
+	for _, a := range assignments {
+		name, value, ok := strings.Cut(a, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --var %q (use name=value)", a)
+		}
+		vars[name] = value
+	}
+	return vars, nil
+}
+
+// describeVars lists a template's required variables for 'templates list'
+func describeVars(t *templates.Template) string {
+	var required []string
+	for _, v := range t.Required() {
+		if v != templates.InputVar {
+			required = append(required, v)
+		}
+	}
+	if len(required) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (vars: %s)", strings.Join(required, ", "))
+}
+
+// completeTemplates completes template names
+func completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	var names []string
+	for _, e := range templates.List(cfg.TemplateDirs()) {
+		if !e.Shadowed {
+			names = append(names, e.Name)
+		}
+	}
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
+
+func init() {
+	templatesCmd.AddCommand(templatesListCmd)
+	templatesCmd.AddCommand(templatesShowCmd)
+	templatesCmd.AddCommand(templatesNewCmd)
+
+	templatesNewCmd.Flags().BoolVar(&templateProject, "project", false, "Create the template in ./"+config.ProjectTemplatesDir+" instead of the user directory")
+	templatesNewCmd.Flags().BoolVar(&templateNoEdit, "no-edit", false, "Don't open the new template in $EDITOR")
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ResolveModel expands a model alias to a model ID, following chains of
+// aliases. Names that are not aliases are returned unchanged.
+func (cfg *Config) ResolveModel(name string) (string, error) {
+	seen := []string{name}
+	for {
+		target, ok := cfg.Aliases[name]
+		if !ok {
+			return name, nil
+		}
+		for _, s := range seen {
+			if s == target {
+				return "", fmt.Errorf("alias cycle: %s", strings.Join(append(seen, target), " -> "))
+			}
+		}
+		seen = append(seen, target)
+		name = target
+	}
+}
+
+// AliasNames returns the configured alias names in sorted order
+func (cfg *Config) AliasNames() []string {
+	names := make([]string, 0, len(cfg.Aliases))
+	for name := range cfg.Aliases {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SetAlias adds or replaces an alias, rejecting one that would create a cycle
+// Alias names can't contain "/" so they never shadow a model ID
+func (cfg *Config) SetAlias(name, target string) error {
+	if name == "" || target == "" {
+		return fmt.Errorf("alias name and model cannot be empty")
+	}
+	if strings.ContainsAny(name, "/ \t") {
+		return fmt.Errorf("alias name %q cannot contain '/' or spaces", name)
+	}
+
+	previous, existed := cfg.Aliases[name]
+	if cfg.Aliases == nil {
+		cfg.Aliases = make(map[string]string)
+	}
+	cfg.Aliases[name] = target
+	if _, err := cfg.ResolveModel(name); err != nil {
+		if existed {
+			cfg.Aliases[name] = previous
+		} else {
+			delete(cfg.Aliases, name)
+		}
+		return err
+	}
+	return nil
+}
+
+// RemoveAlias deletes an alias that no other alias points at
+func (cfg *Config) RemoveAlias(name string) error {
+	if _, ok := cfg.Aliases[name]; !ok {
+		return fmt.Errorf("alias %s not found", name)
+	}
+	for _, other := range cfg.AliasNames() {
+		if cfg.Aliases[other] == name {
+			return fmt.Errorf("alias %s is used by alias %s", name, other)
+		}
+	}
+	delete(cfg.Aliases, name)
+	return nil
+}
+
+// CheckAliases reports every alias that is part of a cycle
+func (cfg *Config) CheckAliases() []string {
+	var problems []string
+	for _, name := range cfg.AliasNames() {
+		if _, err := cfg.ResolveModel(name); err != nil {
+			problems = append(problems, fmt.Sprintf("aliases.%s: %v", name, err))
+		}
+	}
+	return problems
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/util"
+)
+
+// Defaults for cache_ttl and cache_max_size
+const (
+	DefaultCacheTTL     = 24 * time.Hour
+	DefaultCacheMaxSize = 100 * 1024 * 1024
+)
+
+// ParseDuration parses a duration such as "90m", "12h", "7d" or "2w"
+func ParseDuration(value string) (time.Duration, error) {
+	if days, ok := parseDays(value); ok {
+		return time.Duration(days) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 12h, 7d or 2w)", value)
+	}
+	return d, nil
+}
+
+// parseDays parses a whole number of days or weeks such as "7d" or "2w",
+// which time.ParseDuration doesn't accept
+func parseDays(value string) (int, bool) {
+	n, unit := strings.TrimRight(value, "dw"), strings.TrimLeft(value, "0123456789")
+	if unit != "d" && unit != "w" {
+		return 0, false
+	}
+	days, err := strconv.Atoi(n)
+	if err != nil || days <= 0 {
+		return 0, false
+	}
+	if unit == "w" {
+		days *= 7
+	}
+	return days, true
+}
+
+// CacheEnabled reports whether the cache key is set to true
+func (cfg *Config) CacheEnabled() bool {
+	return cfg.Cache != nil && *cfg.Cache
+}
+
+// ResponseCacheDir returns cache_dir, or openrouter/responses in the user
+// cache directory when it is not set
+func (cfg *Config) ResponseCacheDir() string {
+	if cfg.CacheDir != "" {
+		return ExpandHome(cfg.CacheDir)
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = filepath.Dir(GetConfigPath())
+	}
+	return filepath.Join(dir, "openrouter", "responses")
+}
+
+// CacheLimits returns cache_ttl and cache_max_size, or their defaults
+func (cfg *Config) CacheLimits() (time.Duration, int64, error) {
+	ttl, maxSize := DefaultCacheTTL, int64(DefaultCacheMaxSize)
+	if cfg.CacheTTL != "" {
+		d, err := ParseDuration(cfg.CacheTTL)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid cache_ttl: %w", err)
+		}
+		ttl = d
+	}
+	if cfg.CacheMaxSize != "" {
+		n, err := util.ParseSize(cfg.CacheMaxSize)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid cache_max_size: %w", err)
+		}
+		maxSize = n
+	}
+	return ttl, maxSize, nil
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"90m", 90 * time.Minute},
+		{"12h", 12 * time.Hour},
+		{"1h30m", 90 * time.Minute},
+		{"7d", 7 * 24 * time.Hour},
+		{"2w", 14 * 24 * time.Hour},
+	}
+	for _, tt := range tests {
+		if got, err := ParseDuration(tt.value); err != nil || got != tt.want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	for _, bad := range []string{"", "0d", "-1h", "0s", "3x", "d", "1.5d", "7dw"} {
+		if _, err := ParseDuration(bad); err == nil {
+			t.Errorf("ParseDuration(%q) should fail", bad)
+		}
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	// Days are calendar days, so a week crossing a DST change keeps the clock time
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip(err)
+	}
+	start := time.Date(2025, 3, 5, 9, 0, 0, 0, loc)
+	now = func() time.Time { return start }
+	defer func() { now = time.Now }()
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"7d", time.Date(2025, 3, 12, 9, 0, 0, 0, loc)},
+		{"1w", time.Date(2025, 3, 12, 9, 0, 0, 0, loc)},
+		{"36h", start.Add(36 * time.Hour)},
+	}
+	for _, tt := range tests {
+		if got, err := ParseExpiry(tt.value); err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseExpiry(%q) = %v, %v; want %v", tt.value, got, err, tt.want)
+		}
+	}
+	if _, err := ParseExpiry("soon"); err == nil {
+		t.Error(`ParseExpiry("soon") should fail`)
+	}
+}
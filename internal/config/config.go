@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
@@ -13,21 +14,66 @@ var (
 	ErrNoAPIKey = errors.New("no API key found")
 )
 
+// ProjectConfigName is the name of project-local config files
+const ProjectConfigName = ".openrouter.yaml"
+
+// OriginDefault is reported for values that no layer has set
+const OriginDefault = "default"
+
+// explicitPath is the config file chosen with --config
+var explicitPath string
+
+// SetConfigPath overrides the user config file location (the --config flag)
+func SetConfigPath(path string) {
+	explicitPath = path
+}
+
 // Config represents the application configuration
+// The desc, validate and config tags describe each key for the registry in schema.go
 type Config struct {
-	APIKey            string   `yaml:"api_key"`
-	DefaultModel      string   `yaml:"default_model"`
-	DefaultTemp       float64  `yaml:"default_temperature"`
-	DefaultMaxTokens  int      `yaml:"default_max_tokens"`
-	OutputFormat      string   `yaml:"output_format"`
-	APIBaseURL        string   `yaml:"api_base_url"`
-	Timeout           int      `yaml:"timeout"`
-	UnavailableModels []string `yaml:"unavailable_models,omitempty"`
+	ConfigVersion int `yaml:"config_version"`
+
+	APIKey            string             `yaml:"api_key,omitempty" desc:"OpenRouter API key" config:"secret,keysource"`
+	APIKeyCommand     string             `yaml:"api_key_command,omitempty" desc:"Command whose output is the API key" config:"keysource"`
+	APIKeyFile        string             `yaml:"api_key_file,omitempty" desc:"File containing the API key" config:"keysource"`
+	APIKeyEncrypted   string             `yaml:"api_key_encrypted,omitempty" desc:"Passphrase-encrypted API key (see 'config set-key')" config:"secret,keysource"`
+	DefaultModel      string             `yaml:"default_model" desc:"Model used when -m is not given" validate:"required"`
+	DefaultTemp       float64            `yaml:"default_temperature" desc:"Default sampling temperature" validate:"min=0,max=2"`
+	DefaultMaxTokens  int                `yaml:"default_max_tokens" desc:"Default maximum tokens in a response" validate:"min=1"`
+	OutputFormat      string             `yaml:"output_format" desc:"Default output format" validate:"oneof=pretty raw json csv tsv yaml ndjson"`
+	APIBaseURL        string             `yaml:"api_base_url" desc:"OpenRouter API base URL" validate:"url"`
+	Timeout           int                `yaml:"timeout" desc:"Request timeout in seconds" validate:"min=1"`
+	UnavailableModels []UnavailableModel `yaml:"unavailable_models,omitempty" desc:"Models (or globs) hidden from 'openrouter list'"`
+	TemplatesDir      string             `yaml:"templates_dir,omitempty" desc:"Directory of prompt templates (default: templates beside the config file)"`
+	RequestsPerMinute int                `yaml:"requests_per_minute,omitempty" desc:"Most API requests to send per minute (0 for no limit)" validate:"min=0"`
+	TokensPerMinute   int                `yaml:"tokens_per_minute,omitempty" desc:"Most tokens to request per minute (0 for no limit)" validate:"min=0"`
+	Cache             *bool              `yaml:"cache,omitempty" desc:"Reuse responses to identical chat requests from an on-disk cache"`
+	CacheDir          string             `yaml:"cache_dir,omitempty" desc:"Directory of cached responses (default: the user cache directory)"`
+	CacheTTL          string             `yaml:"cache_ttl,omitempty" desc:"How long a cached response is reused, e.g. 12h or 7d (default 24h)" validate:"duration"`
+	CacheMaxSize      string             `yaml:"cache_max_size,omitempty" desc:"Largest the cache may grow before the least recently used responses are removed (default 100M)" validate:"size"`
+
+	// Aliases maps short names to model IDs or to other aliases
+	Aliases map[string]string `yaml:"aliases,omitempty"`
+
+	Routing        *Routing            `yaml:"routing,omitempty"`
+	CurrentProfile string              `yaml:"current_profile,omitempty" desc:"Profile used when --profile is not given" validate:"profile"`
+	Profiles       map[string]*Profile `yaml:"profiles,omitempty"`
+
+	// ActiveProfile is the profile applied by Load, which may come from
+	// --profile or $OPENROUTER_PROFILE rather than current_profile
+	ActiveProfile string `yaml:"-"`
+
+	// Origins records which layer set each key, keyed by YAML name
+	Origins map[string]string `yaml:"-"`
+
+	// Warnings collects problems found while loading, such as unknown keys
+	Warnings []string `yaml:"-"`
 }
 
 // DefaultConfig returns a Config with sensible defaults
 func DefaultConfig() *Config {
 	return &Config{
+		ConfigVersion:    CurrentConfigVersion,
 		DefaultModel:     "openai/gpt-4",
 		DefaultTemp:      1.0,
 		DefaultMaxTokens: 4096,
@@ -37,8 +83,16 @@ func DefaultConfig() *Config {
 	}
 }
 
-// GetConfigPath returns the path to the config file
+// GetConfigPath returns the path to the user config file
+// --config takes precedence over $OPENROUTER_CONFIG, then the default location
 func GetConfigPath() string {
+	if explicitPath != "" {
+		return explicitPath
+	}
+	if envPath := os.Getenv("OPENROUTER_CONFIG"); envPath != "" {
+		return envPath
+	}
+
 	// Try XDG Base Directory spec first
 	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
 		return filepath.Join(xdgConfigHome, "openrouter", "config.yaml")
@@ -54,21 +108,25 @@ func GetConfigPath() string {
 	return filepath.Join(homeDir, ".config", "openrouter", "config.yaml")
 }
 
-// Load loads configuration from file and environment
+// Load loads the effective configuration
+// Layers are applied in order: defaults, the user config file, project-local
+// .openrouter.yaml files from the outermost directory inwards, the selected
+// profile, then OPENROUTER_* environment variables. Use LoadUser when the result will be
+// written back with Save.
 func Load() (*Config, error) {
-	cfg := DefaultConfig()
+	cfg, err := LoadFiles()
+	if err != nil {
+		return nil, err
+	}
 
-	// Try loading from config file
-	configPath := GetConfigPath()
-	if fileData, err := os.ReadFile(configPath); err == nil {
-		if err := yaml.Unmarshal(fileData, cfg); err != nil {
-			return nil, fmt.Errorf("failed to parse config file: %w", err)
-		}
+	// The selected profile overrides file values
+	if err := applyProfile(cfg); err != nil {
+		return nil, err
 	}
 
-	// Environment variable takes precedence
-	if apiKey := os.Getenv("OPENROUTER_API_KEY"); apiKey != "" {
-		cfg.APIKey = apiKey
+	// Environment variables take precedence
+	if err := applyEnv(cfg); err != nil {
+		return nil, err
 	}
 
 	// Don't validate API key here - let the command handle it
@@ -76,6 +134,163 @@ func Load() (*Config, error) {
 	return cfg, nil
 }
 
+// LoadFiles loads defaults merged with the user and project config files,
+// without applying profiles or environment variables
+func LoadFiles() (*Config, error) {
+	cfg, err := LoadUser()
+	if err != nil {
+		return nil, err
+	}
+
+	// Project-local files override the user config
+	for _, path := range FindProjectConfigs() {
+		if err := mergeFile(cfg, path, "project: "+path); err != nil {
+			return nil, err
+		}
+	}
+	return cfg, nil
+}
+
+// LoadUser loads defaults merged with the user config file only
+// A missing file is not an error, though a missing --config or
+// $OPENROUTER_CONFIG file is reported in Warnings.
+func LoadUser() (*Config, error) {
+	cfg := DefaultConfig()
+	configPath := GetConfigPath()
+
+	if _, err := os.Stat(configPath); errors.Is(err, os.ErrNotExist) && configPathExplicit() {
+		cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("config file %s does not exist", configPath))
+	}
+
+	if err := mergeFile(cfg, configPath, "user: "+configPath); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// configPathExplicit reports whether the user config path was chosen with
+// --config or $OPENROUTER_CONFIG rather than defaulted
+func configPathExplicit() bool {
+	return explicitPath != "" || os.Getenv("OPENROUTER_CONFIG") != ""
+}
+
+// mergeFile merges the YAML file at path into cfg, recording origin for each key it sets
+func mergeFile(cfg *Config, path, origin string) error {
+	fileData, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(fileData, &doc); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	root := documentRoot(&doc)
+	if root == nil {
+		return nil
+	}
+
+	// Files are migrated in memory only; 'config migrate' rewrites them
+	if changes, err := migrate(root); err != nil {
+		return fmt.Errorf("config file %s: %w", path, err)
+	} else if len(changes) > 0 {
+		cfg.Warnings = append(cfg.Warnings, fmt.Sprintf("%s uses an older layout (%s); run 'openrouter config migrate %s' to update it",
+			path, strings.Join(changes, "; "), path))
+	}
+	cfg.Warnings = append(cfg.Warnings, unknownKeys(root, path)...)
+
+	var partial PartialConfig
+	if err := root.Decode(&partial); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	partial.Merge(cfg)
+	for _, key := range partial.Keys() {
+		cfg.SetOrigin(key, origin)
+	}
+	return nil
+}
+
+// FindProjectConfigs returns the project-local config files that apply to the
+// working directory, ordered from the outermost directory to the innermost
+func FindProjectConfigs() []string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	userPath, _ := filepath.Abs(GetConfigPath())
+	var paths []string
+	for {
+		path := filepath.Join(dir, ProjectConfigName)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() && path != userPath {
+			paths = append([]string{path}, paths...)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			break
+		}
+		dir = parent
+	}
+	return paths
+}
+
+// ProjectTemplatesDir is the project-local directory of prompt templates
+const ProjectTemplatesDir = ".openrouter/templates"
+
+// TemplateDirs returns the directories searched for prompt templates, from
+// the highest precedence to the lowest: project-local directories from the
+// working directory outwards, then templates_dir
+func (cfg *Config) TemplateDirs() []string {
+	var dirs []string
+	if dir, err := os.Getwd(); err == nil {
+		for {
+			path := filepath.Join(dir, filepath.FromSlash(ProjectTemplatesDir))
+			if info, err := os.Stat(path); err == nil && info.IsDir() {
+				dirs = append(dirs, path)
+			}
+			parent := filepath.Dir(dir)
+			if parent == dir {
+				break
+			}
+			dir = parent
+		}
+	}
+	return append(dirs, cfg.UserTemplatesDir())
+}
+
+// UserTemplatesDir returns templates_dir, or the templates directory beside
+// the user config file when it is not set
+func (cfg *Config) UserTemplatesDir() string {
+	if cfg.TemplatesDir != "" {
+		return ExpandHome(cfg.TemplatesDir)
+	}
+	return filepath.Join(filepath.Dir(GetConfigPath()), "templates")
+}
+
+// SetOrigin records where the value of key came from
+func (cfg *Config) SetOrigin(key, origin string) {
+	if cfg.Origins == nil {
+		cfg.Origins = make(map[string]string)
+	}
+	cfg.Origins[key] = origin
+}
+
+// Origin returns where the value of key came from
+// Nested keys such as routing.sort fall back to the origin of their section
+func (cfg *Config) Origin(key string) string {
+	if origin, ok := cfg.Origins[key]; ok {
+		return origin
+	}
+	if section, _, ok := strings.Cut(key, "."); ok {
+		return cfg.Origin(section)
+	}
+	return OriginDefault
+}
+
 // Save writes configuration to file
 func Save(cfg *Config) error {
 	configPath := GetConfigPath()
@@ -102,20 +317,55 @@ func Save(cfg *Config) error {
 
 // PartialConfig represents a config that can be missing fields
 type PartialConfig struct {
+	// ConfigVersion is read for validation but never merged; loaded
+	// files are always migrated to CurrentConfigVersion
+	ConfigVersion *int `yaml:"config_version"`
+
 	APIKey           *string  `yaml:"api_key"`
+	APIKeyCommand    *string  `yaml:"api_key_command"`
+	APIKeyFile       *string  `yaml:"api_key_file"`
+	APIKeyEncrypted  *string  `yaml:"api_key_encrypted"`
 	DefaultModel     *string  `yaml:"default_model"`
 	DefaultTemp      *float64 `yaml:"default_temperature"`
 	DefaultMaxTokens *int     `yaml:"default_max_tokens"`
 	OutputFormat     *string  `yaml:"output_format"`
 	APIBaseURL       *string  `yaml:"api_base_url"`
 	Timeout          *int     `yaml:"timeout"`
+
+	UnavailableModels []UnavailableModel `yaml:"unavailable_models"`
+	TemplatesDir      *string            `yaml:"templates_dir"`
+	RequestsPerMinute *int               `yaml:"requests_per_minute"`
+	TokensPerMinute   *int               `yaml:"tokens_per_minute"`
+	Cache             *bool              `yaml:"cache"`
+	CacheDir          *string            `yaml:"cache_dir"`
+	CacheTTL          *string            `yaml:"cache_ttl"`
+	CacheMaxSize      *string            `yaml:"cache_max_size"`
+
+	Aliases        map[string]string   `yaml:"aliases"`
+	Routing        *Routing            `yaml:"routing"`
+	CurrentProfile *string             `yaml:"current_profile"`
+	Profiles       map[string]*Profile `yaml:"profiles"`
 }
 
 // Merge merges a partial config into a full config
 func (partial *PartialConfig) Merge(cfg *Config) {
+	// Setting any key source replaces every source from lower layers
+	if partial.APIKey != nil || partial.APIKeyCommand != nil ||
+		partial.APIKeyFile != nil || partial.APIKeyEncrypted != nil {
+		cfg.ClearAPIKey()
+	}
 	if partial.APIKey != nil {
 		cfg.APIKey = *partial.APIKey
 	}
+	if partial.APIKeyCommand != nil {
+		cfg.APIKeyCommand = *partial.APIKeyCommand
+	}
+	if partial.APIKeyFile != nil {
+		cfg.APIKeyFile = *partial.APIKeyFile
+	}
+	if partial.APIKeyEncrypted != nil {
+		cfg.APIKeyEncrypted = *partial.APIKeyEncrypted
+	}
 	if partial.DefaultModel != nil {
 		cfg.DefaultModel = *partial.DefaultModel
 	}
@@ -134,31 +384,149 @@ func (partial *PartialConfig) Merge(cfg *Config) {
 	if partial.Timeout != nil {
 		cfg.Timeout = *partial.Timeout
 	}
+	if partial.UnavailableModels != nil {
+		cfg.UnavailableModels = partial.UnavailableModels
+	}
+	if partial.TemplatesDir != nil {
+		cfg.TemplatesDir = *partial.TemplatesDir
+	}
+	if partial.RequestsPerMinute != nil {
+		cfg.RequestsPerMinute = *partial.RequestsPerMinute
+	}
+	if partial.TokensPerMinute != nil {
+		cfg.TokensPerMinute = *partial.TokensPerMinute
+	}
+	if partial.Cache != nil {
+		cfg.Cache = partial.Cache
+	}
+	if partial.CacheDir != nil {
+		cfg.CacheDir = *partial.CacheDir
+	}
+	if partial.CacheTTL != nil {
+		cfg.CacheTTL = *partial.CacheTTL
+	}
+	if partial.CacheMaxSize != nil {
+		cfg.CacheMaxSize = *partial.CacheMaxSize
+	}
+	if partial.Routing != nil {
+		cfg.Routing = partial.Routing
+	}
+	if partial.CurrentProfile != nil {
+		cfg.CurrentProfile = *partial.CurrentProfile
+	}
+	// Aliases and profiles are merged by name so a project file can add or replace one
+	for name, target := range partial.Aliases {
+		if cfg.Aliases == nil {
+			cfg.Aliases = make(map[string]string)
+		}
+		cfg.Aliases[name] = target
+	}
+	for name, profile := range partial.Profiles {
+		if cfg.Profiles == nil {
+			cfg.Profiles = make(map[string]*Profile)
+		}
+		cfg.Profiles[name] = profile
+	}
 }
 
-// IsModelUnavailable checks if a model is in the unavailable list
-func (cfg *Config) IsModelUnavailable(modelID string) bool {
-	for _, m := range cfg.UnavailableModels {
-		if m == modelID {
-			return true
-		}
+// Keys returns the YAML names of the fields set in the partial config
+func (partial *PartialConfig) Keys() []string {
+	var keys []string
+	if partial.APIKey != nil {
+		keys = append(keys, "api_key")
 	}
-	return false
+	if partial.APIKeyCommand != nil {
+		keys = append(keys, "api_key_command")
+	}
+	if partial.APIKeyFile != nil {
+		keys = append(keys, "api_key_file")
+	}
+	if partial.APIKeyEncrypted != nil {
+		keys = append(keys, "api_key_encrypted")
+	}
+	if partial.DefaultModel != nil {
+		keys = append(keys, "default_model")
+	}
+	if partial.DefaultTemp != nil {
+		keys = append(keys, "default_temperature")
+	}
+	if partial.DefaultMaxTokens != nil {
+		keys = append(keys, "default_max_tokens")
+	}
+	if partial.OutputFormat != nil {
+		keys = append(keys, "output_format")
+	}
+	if partial.APIBaseURL != nil {
+		keys = append(keys, "api_base_url")
+	}
+	if partial.Timeout != nil {
+		keys = append(keys, "timeout")
+	}
+	if partial.UnavailableModels != nil {
+		keys = append(keys, "unavailable_models")
+	}
+	if partial.TemplatesDir != nil {
+		keys = append(keys, "templates_dir")
+	}
+	if partial.RequestsPerMinute != nil {
+		keys = append(keys, "requests_per_minute")
+	}
+	if partial.TokensPerMinute != nil {
+		keys = append(keys, "tokens_per_minute")
+	}
+	if partial.Cache != nil {
+		keys = append(keys, "cache")
+	}
+	if partial.CacheDir != nil {
+		keys = append(keys, "cache_dir")
+	}
+	if partial.CacheTTL != nil {
+		keys = append(keys, "cache_ttl")
+	}
+	if partial.CacheMaxSize != nil {
+		keys = append(keys, "cache_max_size")
+	}
+	if partial.Aliases != nil {
+		keys = append(keys, "aliases")
+	}
+	if partial.Routing != nil {
+		keys = append(keys, "routing")
+	}
+	if partial.CurrentProfile != nil {
+		keys = append(keys, "current_profile")
+	}
+	if partial.Profiles != nil {
+		keys = append(keys, "profiles")
+	}
+	return keys
+}
+
+// IsModelUnavailable reports whether an unexpired entry covers the model
+func (cfg *Config) IsModelUnavailable(modelID string) bool {
+	return cfg.UnavailableEntry(modelID) != nil
 }
 
-// AddUnavailableModel adds a model to the unavailable list
-func (cfg *Config) AddUnavailableModel(modelID string) error {
-	if cfg.IsModelUnavailable(modelID) {
-		return fmt.Errorf("model %s is already marked as unavailable", modelID)
+// AddUnavailableModel adds an entry to the unavailable list
+// An expired entry with the same ID is replaced
+func (cfg *Config) AddUnavailableModel(entry UnavailableModel) error {
+	for i, m := range cfg.UnavailableModels {
+		if m.ID != entry.ID {
+			continue
+		}
+		if !m.Expired() {
+			return fmt.Errorf("model %s is already marked as unavailable", entry.ID)
+		}
+		cfg.UnavailableModels[i] = entry
+		return nil
 	}
-	cfg.UnavailableModels = append(cfg.UnavailableModels, modelID)
+	cfg.UnavailableModels = append(cfg.UnavailableModels, entry)
 	return nil
 }
 
-// RemoveUnavailableModel removes a model from the unavailable list
+// RemoveUnavailableModel removes an entry from the unavailable list by ID
 func (cfg *Config) RemoveUnavailableModel(modelID string) error {
 	for i, m := range cfg.UnavailableModels {
-		if m == modelID {
+		if m.ID == modelID {
 			cfg.UnavailableModels = append(cfg.UnavailableModels[:i], cfg.UnavailableModels[i+1:]...)
 			return nil
 		}
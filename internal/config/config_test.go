@@ -0,0 +1,115 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeProjectConfig writes a .openrouter.yaml into dir, creating it
+func writeProjectConfig(t *testing.T, dir, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, ProjectConfigName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadLayers(t *testing.T) {
+	userPath := writeConfig(t, `default_model: user/model
+default_max_tokens: 100
+output_format: json
+timeout: 30
+current_profile: team
+profiles:
+  team:
+    default_model: team/model
+    timeout: 40
+`)
+	root := filepath.Dir(userPath)
+	outer := writeProjectConfig(t, root, "output_format: yaml\nrequests_per_minute: 10\ntimeout: 20\n")
+	inner := writeProjectConfig(t, filepath.Join(root, "app"), "requests_per_minute: 5\n")
+	t.Chdir(filepath.Join(root, "app"))
+	t.Setenv("OPENROUTER_DEFAULT_MODEL", "env/model")
+
+	if got := FindProjectConfigs(); len(got) != 2 || got[0] != outer || got[1] != inner {
+		t.Fatalf("FindProjectConfigs() = %q, want outermost first", got)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key    string
+		value  string
+		origin string
+	}{
+		{"default_temperature", "1", OriginDefault},
+		{"default_max_tokens", "100", "user: " + userPath},
+		{"output_format", "yaml", "project: " + outer},
+		{"requests_per_minute", "5", "project: " + inner},
+		{"timeout", "40", "profile: team"},
+		{"default_model", "env/model", "env: OPENROUTER_DEFAULT_MODEL"},
+		{"current_profile", "team", "user: " + userPath},
+	}
+	for _, tt := range tests {
+		field, err := LookupField(tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := field.Get(cfg); got != tt.value {
+			t.Errorf("%s = %q, want %q", tt.key, got, tt.value)
+		}
+		if got := cfg.Origin(tt.key); got != tt.origin {
+			t.Errorf("%s origin = %q, want %q", tt.key, got, tt.origin)
+		}
+	}
+
+	// --profile and $OPENROUTER_PROFILE choose the profile ahead of current_profile
+	t.Setenv("OPENROUTER_PROFILE", "missing")
+	if _, err := Load(); err == nil || !strings.Contains(err.Error(), "env: OPENROUTER_PROFILE") {
+		t.Errorf("err = %v, want the missing profile and where it was chosen", err)
+	}
+	SetProfile("team")
+	if cfg, err := Load(); err != nil || cfg.Origin("current_profile") != "flag: --profile" {
+		t.Errorf("current_profile origin = %q, %v", cfg.Origin("current_profile"), err)
+	}
+}
+
+func TestLoadConfigPath(t *testing.T) {
+	userPath := writeConfig(t, "default_model: env/model\n")
+	if got := GetConfigPath(); got != userPath {
+		t.Errorf("GetConfigPath() = %q, want $OPENROUTER_CONFIG", got)
+	}
+
+	// --config wins over $OPENROUTER_CONFIG
+	flagPath := filepath.Join(t.TempDir(), "flag.yaml")
+	if err := os.WriteFile(flagPath, []byte("default_model: flag/model\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	SetConfigPath(flagPath)
+	defer SetConfigPath("")
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultModel != "flag/model" || cfg.Origin("default_model") != "user: "+flagPath {
+		t.Errorf("default_model = %q from %q", cfg.DefaultModel, cfg.Origin("default_model"))
+	}
+
+	// A missing file chosen explicitly loads the defaults with a warning
+	SetConfigPath(filepath.Join(t.TempDir(), "missing.yaml"))
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.DefaultModel != DefaultConfig().DefaultModel || len(cfg.Warnings) != 1 || !strings.Contains(cfg.Warnings[0], "does not exist") {
+		t.Errorf("default_model = %q, warnings = %q", cfg.DefaultModel, cfg.Warnings)
+	}
+}
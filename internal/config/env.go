@@ -0,0 +1,86 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// envVar maps an OPENROUTER_* environment variable onto a config key
+type envVar struct {
+	Name  string
+	Field Field
+}
+
+// envAliases are extra variable names accepted for a key, applied before the canonical name
+var envAliases = map[string]string{
+	"api_base_url": "OPENROUTER_BASE_URL",
+}
+
+// envVars lists every supported environment override, derived from the field
+// registry: routing.sort becomes OPENROUTER_ROUTING_SORT and so on.
+// current_profile is left out in favour of OPENROUTER_PROFILE, and
+// OPENROUTER_API_KEY comes after the other key sources so it wins when several are set
+var envVars = buildEnvVars()
+
+func buildEnvVars() []envVar {
+	var vars, last []envVar
+	for _, f := range Fields() {
+		if f.Key == "current_profile" {
+			continue
+		}
+		if alias, ok := envAliases[f.Key]; ok {
+			vars = append(vars, envVar{alias, f})
+		}
+		ev := envVar{EnvName(f.Key), f}
+		if f.Key == "api_key" {
+			last = append(last, ev)
+			continue
+		}
+		vars = append(vars, ev)
+	}
+	return append(vars, last...)
+}
+
+// EnvName returns the OPENROUTER_* variable that overrides key
+func EnvName(key string) string {
+	return "OPENROUTER_" + strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// applyEnv overlays OPENROUTER_* environment variables onto cfg
+// Empty variables are ignored; values that fail to parse are reported by name
+func applyEnv(cfg *Config) error {
+	for _, ev := range envVars {
+		value := strings.TrimSpace(os.Getenv(ev.Name))
+		if value == "" {
+			continue
+		}
+		if err := ev.Field.Set(cfg, value); err != nil {
+			return fmt.Errorf("invalid %s=%q: %w", ev.Name, value, err)
+		}
+		cfg.SetOrigin(ev.Field.Key, "env: "+ev.Name)
+	}
+	return nil
+}
+
+// routing returns a copy of cfg.Routing for modification, creating it if needed
+// Copying keeps a profile's routing preferences from being changed in place
+func (cfg *Config) routing() *Routing {
+	routing := Routing{}
+	if cfg.Routing != nil {
+		routing = *cfg.Routing
+	}
+	cfg.Routing = &routing
+	return cfg.Routing
+}
+
+// splitList splits a comma-separated list, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
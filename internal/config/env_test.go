@@ -0,0 +1,114 @@
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestApplyEnv(t *testing.T) {
+	writeConfig(t, `api_key_file: /keys/openrouter
+profiles:
+  team:
+    routing:
+      order: [anthropic]
+`)
+	env := map[string]string{
+		"OPENROUTER_BASE_URL":                "http://alias.example",
+		"OPENROUTER_API_BASE_URL":            "http://canonical.example",
+		"OPENROUTER_TIMEOUT":                 " 15 ",
+		"OPENROUTER_DEFAULT_TEMPERATURE":     "0.3",
+		"OPENROUTER_CACHE":                   "true",
+		"OPENROUTER_ROUTING_ORDER":           "openai, ,azure",
+		"OPENROUTER_ROUTING_ALLOW_FALLBACKS": "false",
+		"OPENROUTER_API_KEY":                 "sk-or-v1-env",
+		"OPENROUTER_PROFILE":                 "team",
+	}
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"api_base_url":            "http://canonical.example", // The canonical name wins over the alias
+		"timeout":                 "15",
+		"default_temperature":     "0.3",
+		"cache":                   "true",
+		"routing.order":           "openai,azure",
+		"routing.allow_fallbacks": "false",
+		"api_key":                 "sk-or-v1-env",
+		"api_key_file":            "", // OPENROUTER_API_KEY replaces the other key sources
+		"default_max_tokens":      "4096",
+	}
+	for key, value := range want {
+		field, err := LookupField(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := field.Get(cfg); got != value {
+			t.Errorf("%s = %q, want %q", key, got, value)
+		}
+	}
+	if got := cfg.Origin("routing.order"); got != "env: OPENROUTER_ROUTING_ORDER" {
+		t.Errorf("routing.order origin = %q", got)
+	}
+	if got := cfg.Profiles["team"].Routing.Order; !reflect.DeepEqual(got, []string{"anthropic"}) {
+		t.Errorf("the profile's routing was changed in place: %q", got)
+	}
+}
+
+func TestApplyEnvErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{"OPENROUTER_TIMEOUT", "soon", "must be an integer"},
+		{"OPENROUTER_TIMEOUT", "0", "must be at least 1"},
+		{"OPENROUTER_DEFAULT_TEMPERATURE", "hot", "must be a number"},
+		{"OPENROUTER_DEFAULT_TEMPERATURE", "2.5", "must be between 0 and 2"},
+		{"OPENROUTER_CACHE", "maybe", "must be true or false"},
+		{"OPENROUTER_OUTPUT_FORMAT", "xml", "must be one of"},
+		{"OPENROUTER_BASE_URL", "localhost:8080", "must be an http or https URL"},
+		{"OPENROUTER_CACHE_TTL", "forever", "invalid duration"},
+		{"OPENROUTER_CACHE_MAX_SIZE", "lots", "invalid size"},
+		{"OPENROUTER_ROUTING_ALLOW_FALLBACKS", "nah", "must be true or false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name+"="+tt.value, func(t *testing.T) {
+			writeConfig(t, "")
+			t.Setenv(tt.name, tt.value)
+			_, err := Load()
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if msg := err.Error(); !strings.Contains(msg, tt.name+"=") || !strings.Contains(msg, tt.want) {
+				t.Errorf("error %q should name %s and say %q", msg, tt.name, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnvVars(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, ev := range envVars {
+		if seen[ev.Name] {
+			t.Errorf("%s is listed twice", ev.Name)
+		}
+		seen[ev.Name] = true
+		if ev.Field.Key == "current_profile" {
+			t.Error("current_profile should only be set with OPENROUTER_PROFILE")
+		}
+	}
+	for _, f := range Fields() {
+		if f.Key != "current_profile" && !seen[EnvName(f.Key)] {
+			t.Errorf("%s has no environment variable", f.Key)
+		}
+	}
+	if last := envVars[len(envVars)-1]; last.Name != "OPENROUTER_API_KEY" {
+		t.Errorf("last variable is %s, want OPENROUTER_API_KEY", last.Name)
+	}
+}
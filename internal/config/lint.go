@@ -0,0 +1,124 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unknownKeys reports keys in a config mapping that no field reads, with a
+// suggestion when the key looks like a typo of a known one
+func unknownKeys(root *yaml.Node, path string) []string {
+	var warnings []string
+	check := func(mapping *yaml.Node, prefix string, known []string) {
+		for i := 0; i+1 < len(mapping.Content); i += 2 {
+			key := mapping.Content[i]
+			if contains(known, key.Value) {
+				continue
+			}
+			warning := fmt.Sprintf("%s:%d: unknown key %q", path, key.Line, prefix+key.Value)
+			if suggestion := closest(key.Value, known); suggestion != "" {
+				warning += fmt.Sprintf(" (did you mean %q?)", prefix+suggestion)
+			}
+			warnings = append(warnings, warning)
+		}
+	}
+	checkRouting := func(parent *yaml.Node, prefix string) {
+		if routing := mappingValue(parent, "routing"); routing != nil && routing.Kind == yaml.MappingNode {
+			check(routing, prefix+"routing.", yamlKeys(Routing{}))
+		}
+	}
+
+	check(root, "", yamlKeys(PartialConfig{}))
+	checkRouting(root, "")
+	if profiles := mappingValue(root, "profiles"); profiles != nil && profiles.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			profile := profiles.Content[i+1]
+			if profile.Kind != yaml.MappingNode {
+				continue
+			}
+			prefix := "profiles." + profiles.Content[i].Value + "."
+			check(profile, prefix, yamlKeys(Profile{}))
+			checkRouting(profile, prefix)
+		}
+	}
+	return warnings
+}
+
+// yamlKeys returns the YAML names of a struct's fields
+func yamlKeys(v any) []string {
+	t := reflect.TypeOf(v)
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if name := yamlName(t.Field(i)); name != "" && name != "-" {
+			keys = append(keys, name)
+		}
+	}
+	return keys
+}
+
+// closest returns the candidate nearest to s by edit distance, or "" if
+// none is close enough to be a likely typo
+func closest(s string, candidates []string) string {
+	best, bestDist := "", len(s)/3+1
+	for _, c := range candidates {
+		if d := editDistance(s, c); d <= bestDist {
+			best, bestDist = c, d
+		}
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// Lint checks the contents of a config file without loading it
+// It reports parse errors, unknown keys and invalid values; an empty
+// result means the file can be saved
+func Lint(data []byte, name string) []string {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return []string{err.Error()}
+	}
+	root := documentRoot(&doc)
+	if root == nil {
+		return nil
+	}
+	if _, err := migrate(root); err != nil {
+		return []string{err.Error()}
+	}
+
+	problems := unknownKeys(root, name)
+	var partial PartialConfig
+	if err := root.Decode(&partial); err != nil {
+		return append(problems, err.Error())
+	}
+
+	cfg := DefaultConfig()
+	partial.Merge(cfg)
+	for _, field := range Fields() {
+		if err := field.Validate(cfg); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", field.Key, err))
+		}
+	}
+	return append(problems, cfg.CheckAliases()...)
+}
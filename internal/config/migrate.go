@@ -0,0 +1,170 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentConfigVersion is the config_version written by Save
+// Files without config_version are version 0, though one with nothing to
+// migrate is treated as current, since project files are written by hand
+const CurrentConfigVersion = 1
+
+// migration upgrades a config mapping from version From to From+1,
+// returning a short description of each change it made
+type migration struct {
+	From  int
+	Apply func(root *yaml.Node) []string
+}
+
+// migrations are applied in order to bring a file up to CurrentConfigVersion
+var migrations = []migration{
+	{0, migrateV0},
+}
+
+// migrateV0 drops the empty api_key that releases before config_version
+// wrote when no key was set, which would otherwise override keys from the
+// environment or a profile
+func migrateV0(root *yaml.Node) []string {
+	if key := mappingValue(root, "api_key"); key != nil && key.Kind == yaml.ScalarNode && key.Value == "" {
+		deleteMappingKey(root, "api_key")
+		return []string{"removed empty api_key"}
+	}
+	return nil
+}
+
+// migrate upgrades a config mapping in place to CurrentConfigVersion
+// It returns the changes made, or none when the file is already current
+func migrate(root *yaml.Node) ([]string, error) {
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected a mapping of settings at line %d", root.Line)
+	}
+
+	version := 0
+	versioned := false
+	if node := mappingValue(root, "config_version"); node != nil {
+		versioned = true
+		v, err := strconv.Atoi(node.Value)
+		if err != nil || v < 0 {
+			return nil, fmt.Errorf("config_version must be a non-negative integer")
+		}
+		version = v
+	}
+	if version > CurrentConfigVersion {
+		return nil, fmt.Errorf("config_version %d is newer than this release supports (%d); upgrade openrouter",
+			version, CurrentConfigVersion)
+	}
+	if version == CurrentConfigVersion {
+		return nil, nil
+	}
+
+	var changes []string
+	for _, m := range migrations {
+		if m.From >= version {
+			changes = append(changes, m.Apply(root)...)
+		}
+	}
+	if len(changes) == 0 && !versioned {
+		return nil, nil
+	}
+
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(CurrentConfigVersion)}
+	if node := mappingValue(root, "config_version"); node != nil {
+		*node = *value
+	} else {
+		key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "config_version"}
+		root.Content = append([]*yaml.Node{key, value}, root.Content...)
+	}
+	return append(changes, fmt.Sprintf("set config_version to %d", CurrentConfigVersion)), nil
+}
+
+// MigrateFile rewrites the config file at path in the current layout,
+// keeping comments and saving the original alongside it as path.bak
+// It returns a description of the changes, or "" if none were needed.
+// Loading never calls it: other files are migrated in memory only.
+func MigrateFile(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return "", fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	root := documentRoot(&doc)
+	if root == nil {
+		return "", nil
+	}
+
+	changes, err := migrate(root)
+	if err != nil {
+		return "", fmt.Errorf("config file %s: %w", path, err)
+	}
+	if len(changes) == 0 {
+		return "", nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return "", fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+
+	mode := os.FileMode(0600)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	backup := path + ".bak"
+	if err := os.WriteFile(backup, data, 0600); err != nil {
+		return "", fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), mode); err != nil {
+		return "", fmt.Errorf("failed to migrate %s: %w", path, err)
+	}
+
+	return fmt.Sprintf("migrated %s to config_version %d (%s); the original is saved as %s",
+		path, CurrentConfigVersion, strings.Join(changes, "; "), backup), nil
+}
+
+// documentRoot returns the top-level mapping of a parsed YAML document,
+// or nil for an empty document
+func documentRoot(doc *yaml.Node) *yaml.Node {
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0]
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in a mapping, or nil
+func mappingValue(mapping *yaml.Node, key string) *yaml.Node {
+	if mapping.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// deleteMappingKey removes key and its value from a mapping
+func deleteMappingKey(mapping *yaml.Node, key string) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+)
+
+// Routing holds OpenRouter provider routing preferences sent with chat requests
+type Routing struct {
+	Order          []string `yaml:"order,omitempty" desc:"Providers to try first, in order"`
+	AllowFallbacks *bool    `yaml:"allow_fallbacks,omitempty" desc:"Allow providers outside routing.order"`
+	Only           []string `yaml:"only,omitempty" desc:"Only use these providers"`
+	Ignore         []string `yaml:"ignore,omitempty" desc:"Never use these providers"`
+	DataCollection string   `yaml:"data_collection,omitempty" desc:"Whether providers may store data" validate:"oneof=allow deny"`
+	Sort           string   `yaml:"sort,omitempty" desc:"Provider sort order" validate:"oneof=price throughput latency"`
+}
+
+// Profile is a named set of overrides, e.g. for a team key or a local server
+// Unset fields fall back to the top-level config values
+type Profile struct {
+	APIKey          *string  `yaml:"api_key,omitempty"`
+	APIKeyCommand   *string  `yaml:"api_key_command,omitempty"`
+	APIKeyFile      *string  `yaml:"api_key_file,omitempty"`
+	APIKeyEncrypted *string  `yaml:"api_key_encrypted,omitempty"`
+	APIBaseURL      *string  `yaml:"api_base_url,omitempty"`
+	DefaultModel    *string  `yaml:"default_model,omitempty"`
+	Timeout         *int     `yaml:"timeout,omitempty"`
+	Routing         *Routing `yaml:"routing,omitempty"`
+}
+
+// explicitProfile is the profile chosen with --profile
+var explicitProfile string
+
+// SetProfile selects a profile by name (the --profile flag)
+func SetProfile(name string) {
+	explicitProfile = name
+}
+
+// SelectedProfile returns the profile to apply and where the choice came from
+// --profile takes precedence over $OPENROUTER_PROFILE, then current_profile
+func SelectedProfile(cfg *Config) (string, string) {
+	if explicitProfile != "" {
+		return explicitProfile, "flag: --profile"
+	}
+	if name := os.Getenv("OPENROUTER_PROFILE"); name != "" {
+		return name, "env: OPENROUTER_PROFILE"
+	}
+	return cfg.CurrentProfile, cfg.Origin("current_profile")
+}
+
+// applyProfile overlays the selected profile onto cfg
+func applyProfile(cfg *Config) error {
+	name, origin := SelectedProfile(cfg)
+	if name == "" {
+		return nil
+	}
+
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %q not found (from %s)", name, origin)
+	}
+
+	cfg.ActiveProfile = name
+	cfg.SetOrigin("current_profile", origin)
+
+	fromProfile := "profile: " + name
+	if profile.APIKey != nil || profile.APIKeyCommand != nil ||
+		profile.APIKeyFile != nil || profile.APIKeyEncrypted != nil {
+		cfg.ClearAPIKey()
+	}
+	if profile.APIKey != nil {
+		cfg.APIKey = *profile.APIKey
+		cfg.SetOrigin("api_key", fromProfile)
+	}
+	if profile.APIKeyCommand != nil {
+		cfg.APIKeyCommand = *profile.APIKeyCommand
+		cfg.SetOrigin("api_key_command", fromProfile)
+	}
+	if profile.APIKeyFile != nil {
+		cfg.APIKeyFile = *profile.APIKeyFile
+		cfg.SetOrigin("api_key_file", fromProfile)
+	}
+	if profile.APIKeyEncrypted != nil {
+		cfg.APIKeyEncrypted = *profile.APIKeyEncrypted
+		cfg.SetOrigin("api_key_encrypted", fromProfile)
+	}
+	if profile.APIBaseURL != nil {
+		cfg.APIBaseURL = *profile.APIBaseURL
+		cfg.SetOrigin("api_base_url", fromProfile)
+	}
+	if profile.DefaultModel != nil {
+		cfg.DefaultModel = *profile.DefaultModel
+		cfg.SetOrigin("default_model", fromProfile)
+	}
+	if profile.Timeout != nil {
+		cfg.Timeout = *profile.Timeout
+		cfg.SetOrigin("timeout", fromProfile)
+	}
+	if profile.Routing != nil {
+		cfg.Routing = profile.Routing
+		cfg.SetOrigin("routing", fromProfile)
+	}
+	return nil
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func (cfg *Config) ProfileNames() []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddProfile adds a new profile
+func (cfg *Config) AddProfile(name string, profile *Profile) error {
+	if name == "" {
+		return fmt.Errorf("profile name cannot be empty")
+	}
+	if _, ok := cfg.Profiles[name]; ok {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = make(map[string]*Profile)
+	}
+	cfg.Profiles[name] = profile
+	return nil
+}
+
+// DeleteProfile removes a profile, clearing current_profile if it was active
+func (cfg *Config) DeleteProfile(name string) error {
+	if _, ok := cfg.Profiles[name]; !ok {
+		return fmt.Errorf("profile %s not found", name)
+	}
+	delete(cfg.Profiles, name)
+	if cfg.CurrentProfile == name {
+		cfg.CurrentProfile = ""
+	}
+	return nil
+}
+
+// CopyProfile duplicates the profile src as dst
+func (cfg *Config) CopyProfile(src, dst string) error {
+	profile, ok := cfg.Profiles[src]
+	if !ok {
+		return fmt.Errorf("profile %s not found", src)
+	}
+
+	clone := Profile{
+		APIKey:          clonePtr(profile.APIKey),
+		APIKeyCommand:   clonePtr(profile.APIKeyCommand),
+		APIKeyFile:      clonePtr(profile.APIKeyFile),
+		APIKeyEncrypted: clonePtr(profile.APIKeyEncrypted),
+		APIBaseURL:      clonePtr(profile.APIBaseURL),
+		DefaultModel:    clonePtr(profile.DefaultModel),
+		Timeout:         clonePtr(profile.Timeout),
+	}
+	if profile.Routing != nil {
+		routing := *profile.Routing
+		routing.AllowFallbacks = clonePtr(profile.Routing.AllowFallbacks)
+		routing.Order = append([]string(nil), profile.Routing.Order...)
+		routing.Only = append([]string(nil), profile.Routing.Only...)
+		routing.Ignore = append([]string(nil), profile.Routing.Ignore...)
+		clone.Routing = &routing
+	}
+	return cfg.AddProfile(dst, &clone)
+}
+
+// UseProfile makes name the current profile
+func (cfg *Config) UseProfile(name string) error {
+	if _, ok := cfg.Profiles[name]; !ok {
+		return fmt.Errorf("profile %s not found", name)
+	}
+	cfg.CurrentProfile = name
+	return nil
+}
+
+// clonePtr returns a pointer to a copy of *p, or nil
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
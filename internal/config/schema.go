@@ -0,0 +1,380 @@
+package config
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kdevrou/openrouter-cli/internal/util"
+)
+
+// Field types reported by Field.Type
+const (
+	TypeString = "string"
+	TypeInt    = "int"
+	TypeFloat  = "float"
+	TypeBool   = "bool"
+	TypeList   = "list"
+)
+
+// Field describes one config key, derived from the tags on Config and Routing
+type Field struct {
+	Key         string
+	Type        string
+	Description string
+	Choices     []string // allowed values, from validate:"oneof=..."
+	Secret      bool     // never printed in full
+	KeySource   bool     // one of the API key backends; setting it clears the others
+
+	index    []int        // reflect field index path from Config
+	nested   bool         // field lives in the Routing struct
+	elem     reflect.Type // list element type; non-string elements implement encoding.TextUnmarshaler
+	min, max *float64
+	required bool
+	url      bool
+	profile  bool
+	duration bool
+	size     bool
+}
+
+// fields is the registry built from the Config struct tags
+var fields = buildFields()
+
+// Fields returns every settable config key in declaration order
+func Fields() []Field {
+	return fields
+}
+
+// FieldNames returns every settable config key in sorted order
+func FieldNames() []string {
+	names := make([]string, len(fields))
+	for i, f := range fields {
+		names[i] = f.Key
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupField returns the field for key
+func LookupField(key string) (Field, error) {
+	for _, f := range fields {
+		if f.Key == key {
+			return f, nil
+		}
+	}
+	if suggestion := closest(key, FieldNames()); suggestion != "" {
+		return Field{}, fmt.Errorf("unknown config key: %s (did you mean %s?)", key, suggestion)
+	}
+	return Field{}, fmt.Errorf("unknown config key: %s (see 'openrouter config list-keys')", key)
+}
+
+// buildFields walks Config, descending into the Routing struct as routing.*
+// Fields without a desc tag (profiles, runtime state) are not settable keys
+func buildFields() []Field {
+	var out []Field
+	cfgType := reflect.TypeOf(Config{})
+	for i := 0; i < cfgType.NumField(); i++ {
+		sf := cfgType.Field(i)
+		name := yamlName(sf)
+		if sf.Type == reflect.TypeOf(&Routing{}) {
+			routingType := sf.Type.Elem()
+			for j := 0; j < routingType.NumField(); j++ {
+				rf := routingType.Field(j)
+				if f, ok := newField(rf, name+"."+yamlName(rf), []int{i, j}); ok {
+					f.nested = true
+					out = append(out, f)
+				}
+			}
+			continue
+		}
+		if f, ok := newField(sf, name, []int{i}); ok {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// newField builds a Field from a struct field's tags
+func newField(sf reflect.StructField, key string, index []int) (Field, bool) {
+	desc, ok := sf.Tag.Lookup("desc")
+	if !ok {
+		return Field{}, false
+	}
+
+	f := Field{Key: key, Description: desc, index: index}
+	switch sf.Type.Kind() {
+	case reflect.String:
+		f.Type = TypeString
+	case reflect.Int:
+		f.Type = TypeInt
+	case reflect.Float64:
+		f.Type = TypeFloat
+	case reflect.Slice:
+		f.Type = TypeList
+		f.elem = sf.Type.Elem()
+	case reflect.Ptr:
+		f.Type = TypeBool
+		f.Choices = []string{"true", "false"}
+	default:
+		panic(fmt.Sprintf("config: unsupported type %s for %s", sf.Type, key))
+	}
+
+	for _, opt := range strings.Split(sf.Tag.Get("config"), ",") {
+		switch opt {
+		case "secret":
+			f.Secret = true
+		case "keysource":
+			f.KeySource = true
+		}
+	}
+
+	for _, rule := range strings.Split(sf.Tag.Get("validate"), ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "":
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				panic(fmt.Sprintf("config: bad %s rule for %s", name, key))
+			}
+			if name == "min" {
+				f.min = &n
+			} else {
+				f.max = &n
+			}
+		case "oneof":
+			f.Choices = strings.Fields(arg)
+		case "required":
+			f.required = true
+		case "url":
+			f.url = true
+		case "profile":
+			f.profile = true
+		case "duration":
+			f.duration = true
+		case "size":
+			f.size = true
+		default:
+			panic(fmt.Sprintf("config: unknown validate rule %q for %s", rule, key))
+		}
+	}
+	return f, true
+}
+
+// yamlName returns the YAML key of a struct field
+func yamlName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("yaml"), ",")
+	return name
+}
+
+// value returns the reflect value of f in cfg, or an invalid Value when the
+// enclosing routing struct is nil
+func (f Field) value(cfg *Config) reflect.Value {
+	v := reflect.ValueOf(cfg).Elem().Field(f.index[0])
+	if !f.nested {
+		return v
+	}
+	if v.IsNil() {
+		return reflect.Value{}
+	}
+	return v.Elem().Field(f.index[1])
+}
+
+// Get returns the value of f in cfg formatted as 'config set' accepts it
+// Lists are comma-separated and an unset bool is empty
+func (f Field) Get(cfg *Config) string {
+	v := f.value(cfg)
+	if !v.IsValid() {
+		return ""
+	}
+	switch f.Type {
+	case TypeInt:
+		return strconv.FormatInt(v.Int(), 10)
+	case TypeFloat:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	case TypeList:
+		items := make([]string, v.Len())
+		for i := range items {
+			item := v.Index(i)
+			if m, ok := item.Interface().(encoding.TextMarshaler); ok {
+				text, _ := m.MarshalText()
+				items[i] = string(text)
+			} else {
+				items[i] = item.String()
+			}
+		}
+		return strings.Join(items, ",")
+	case TypeBool:
+		if v.IsNil() {
+			return ""
+		}
+		return strconv.FormatBool(v.Elem().Bool())
+	}
+	return v.String()
+}
+
+// IsZero reports whether f is unset or empty in cfg
+func (f Field) IsZero(cfg *Config) bool {
+	v := f.value(cfg)
+	return !v.IsValid() || v.IsZero() || (f.Type == TypeList && v.Len() == 0)
+}
+
+// Set parses and validates value, then stores it in cfg
+// Setting one API key backend clears the others
+func (f Field) Set(cfg *Config, value string) error {
+	parsed, err := f.parse(value)
+	if err != nil {
+		return err
+	}
+	if err := f.check(cfg, parsed); err != nil {
+		return err
+	}
+
+	if f.KeySource {
+		cfg.ClearAPIKey()
+	}
+	if f.nested {
+		// routing() copies, so a profile's routing is never changed in place
+		cfg.routing()
+	}
+	f.value(cfg).Set(parsed)
+	return nil
+}
+
+// Unset restores f to its default value in cfg
+// An empty routing section is removed entirely
+func (f Field) Unset(cfg *Config) {
+	v := f.value(cfg)
+	if !v.IsValid() {
+		return
+	}
+	if f.nested {
+		cfg.routing()
+		v = f.value(cfg)
+	}
+
+	if def := f.value(DefaultConfig()); def.IsValid() {
+		v.Set(def)
+	} else {
+		v.Set(reflect.Zero(v.Type()))
+	}
+
+	if f.nested && reflect.ValueOf(*cfg.Routing).IsZero() {
+		cfg.Routing = nil
+	}
+}
+
+// Validate checks the current value of f in cfg
+func (f Field) Validate(cfg *Config) error {
+	v := f.value(cfg)
+	if !v.IsValid() {
+		return nil
+	}
+	return f.check(cfg, v)
+}
+
+// parse converts a command-line or environment string to f's Go type
+func (f Field) parse(value string) (reflect.Value, error) {
+	switch f.Type {
+	case TypeInt:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("must be an integer")
+		}
+		return reflect.ValueOf(n), nil
+	case TypeFloat:
+		n, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("must be a number")
+		}
+		return reflect.ValueOf(n), nil
+	case TypeBool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("must be true or false")
+		}
+		return reflect.ValueOf(&b), nil
+	case TypeList:
+		items := splitList(value)
+		list := reflect.MakeSlice(reflect.SliceOf(f.elem), len(items), len(items))
+		for i, item := range items {
+			elem := list.Index(i)
+			if u, ok := elem.Addr().Interface().(encoding.TextUnmarshaler); ok {
+				if err := u.UnmarshalText([]byte(item)); err != nil {
+					return reflect.Value{}, err
+				}
+			} else {
+				elem.SetString(item)
+			}
+		}
+		if len(items) == 0 {
+			list = reflect.Zero(list.Type())
+		}
+		return list, nil
+	}
+	return reflect.ValueOf(value), nil
+}
+
+// check applies f's validation rules to v
+// Empty optional strings are allowed so 'oneof' and 'url' keys can be cleared
+func (f Field) check(cfg *Config, v reflect.Value) error {
+	switch f.Type {
+	case TypeInt, TypeFloat:
+		n := v.Convert(reflect.TypeOf(float64(0))).Float()
+		switch {
+		case f.min != nil && f.max != nil && (n < *f.min || n > *f.max):
+			return fmt.Errorf("must be between %g and %g", *f.min, *f.max)
+		case f.min != nil && n < *f.min:
+			return fmt.Errorf("must be at least %g", *f.min)
+		case f.max != nil && n > *f.max:
+			return fmt.Errorf("must be at most %g", *f.max)
+		}
+	case TypeString:
+		s := v.String()
+		if s == "" {
+			if f.required {
+				return fmt.Errorf("must not be empty")
+			}
+			return nil
+		}
+		if len(f.Choices) > 0 && !contains(f.Choices, s) {
+			return fmt.Errorf("must be one of: %s", strings.Join(f.Choices, ", "))
+		}
+		if f.url {
+			u, err := url.Parse(s)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return fmt.Errorf("must be an http or https URL")
+			}
+		}
+		if f.profile {
+			if _, ok := cfg.Profiles[s]; !ok {
+				return fmt.Errorf("profile %s not found", s)
+			}
+		}
+		if f.duration {
+			if _, err := ParseDuration(s); err != nil {
+				return err
+			}
+		}
+		if f.size {
+			if _, err := util.ParseSize(s); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// contains reports whether list includes s
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
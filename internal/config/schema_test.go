@@ -0,0 +1,109 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFieldSet(t *testing.T) {
+	tests := []struct {
+		key   string
+		value string
+		want  string // error substring, or "" if the value is accepted
+	}{
+		{"default_temperature", "0", ""},
+		{"default_temperature", "2", ""},
+		{"default_temperature", "-0.1", "must be between 0 and 2"},
+		{"default_temperature", "2.1", "must be between 0 and 2"},
+		{"default_temperature", "warm", "must be a number"},
+		{"default_max_tokens", "1", ""},
+		{"default_max_tokens", "0", "must be at least 1"},
+		{"timeout", "-5", "must be at least 1"},
+		{"timeout", "1.5", "must be an integer"},
+		{"requests_per_minute", "0", ""},
+		{"requests_per_minute", "-1", "must be at least 0"},
+		{"output_format", "ndjson", ""},
+		{"output_format", "xml", "must be one of: pretty, raw, json, csv, tsv, yaml, ndjson"},
+		{"routing.sort", "price", ""},
+		{"routing.sort", "cheapest", "must be one of: price, throughput, latency"},
+		{"routing.sort", "", ""}, // Optional choices can be cleared
+		{"api_base_url", "https://openrouter.ai/api/v1", ""},
+		{"api_base_url", "http://localhost:8080", ""},
+		{"api_base_url", "ftp://example.com", "must be an http or https URL"},
+		{"api_base_url", "localhost:8080", "must be an http or https URL"},
+		{"api_base_url", "https://", "must be an http or https URL"},
+		{"default_model", "", "must not be empty"},
+		{"cache_ttl", "7d", ""},
+		{"cache_ttl", "0h", "invalid duration"},
+		{"cache_ttl", "a while", "invalid duration"},
+		{"cache_max_size", "512k", ""},
+		{"cache_max_size", "huge", "invalid size"},
+		{"cache_max_size", "-1M", "invalid size"},
+		{"cache", "yes", "must be true or false"},
+		{"current_profile", "team", ""},
+		{"current_profile", "missing", "profile missing not found"},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig()
+		cfg.Profiles = map[string]*Profile{"team": {}}
+		field, err := LookupField(tt.key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		before := field.Get(cfg)
+
+		err = field.Set(cfg, tt.value)
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("Set(%s, %q): %v", tt.key, tt.value, err)
+		case tt.want == "":
+			if got := field.Get(cfg); got != tt.value {
+				t.Errorf("Set(%s, %q) stored %q", tt.key, tt.value, got)
+			}
+		case err == nil || !strings.Contains(err.Error(), tt.want):
+			t.Errorf("Set(%s, %q) = %v, want an error containing %q", tt.key, tt.value, err, tt.want)
+		case field.Get(cfg) != before:
+			t.Errorf("Set(%s, %q) failed but changed the value to %q", tt.key, tt.value, field.Get(cfg))
+		}
+	}
+}
+
+func TestFieldSetKeySource(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.APIKey = "sk-or-v1-plain"
+	field, _ := LookupField("api_key_command")
+	if err := field.Set(cfg, "pass show openrouter"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "" || cfg.APIKeyBackend() != BackendCommand {
+		t.Errorf("api_key = %q, backend = %q", cfg.APIKey, cfg.APIKeyBackend())
+	}
+}
+
+func TestFieldSetRouting(t *testing.T) {
+	shared := &Routing{Sort: "price"}
+	cfg := DefaultConfig()
+	cfg.Routing = shared
+	sort, _ := LookupField("routing.sort")
+	if err := sort.Set(cfg, "latency"); err != nil {
+		t.Fatal(err)
+	}
+	if shared.Sort != "price" || cfg.Routing.Sort != "latency" {
+		t.Errorf("shared sort = %q, config sort = %q; the routing section should be copied", shared.Sort, cfg.Routing.Sort)
+	}
+
+	// Unsetting the last routing key removes the section
+	sort.Unset(cfg)
+	if cfg.Routing != nil {
+		t.Errorf("routing = %+v, want nil", cfg.Routing)
+	}
+}
+
+func TestLookupField(t *testing.T) {
+	if _, err := LookupField("default_modle"); err == nil || !strings.Contains(err.Error(), "did you mean default_model?") {
+		t.Errorf("err = %v, want a suggestion", err)
+	}
+	if _, err := LookupField("profiles"); err == nil {
+		t.Error("profiles should not be a settable key")
+	}
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Secret backends for the API key, in the order ResolveAPIKey tries them
+const (
+	BackendPlain     = "plain"     // api_key stored in the config file
+	BackendCommand   = "command"   // api_key_command prints the key
+	BackendFile      = "file"      // api_key_file contains the key
+	BackendEncrypted = "encrypted" // api_key_encrypted holds the key sealed with a passphrase
+)
+
+// ErrWrongPassphrase is returned when an encrypted key cannot be decrypted
+var ErrWrongPassphrase = errors.New("wrong passphrase or corrupted api_key_encrypted")
+
+const (
+	encryptedVersion = "v1"
+	kdfIterations    = 600000
+	kdfSaltSize      = 16
+)
+
+// APIKeyBackend reports which backend supplies the API key, or "" if none is configured
+func (cfg *Config) APIKeyBackend() string {
+	switch {
+	case cfg.APIKey != "":
+		return BackendPlain
+	case cfg.APIKeyCommand != "":
+		return BackendCommand
+	case cfg.APIKeyFile != "":
+		return BackendFile
+	case cfg.APIKeyEncrypted != "":
+		return BackendEncrypted
+	}
+	return ""
+}
+
+// ClearAPIKey removes the API key from every backend
+// Layers that set any key source call this first, so a profile using
+// api_key_command is not shadowed by a plain api_key from the user config
+func (cfg *Config) ClearAPIKey() {
+	cfg.APIKey = ""
+	cfg.APIKeyCommand = ""
+	cfg.APIKeyFile = ""
+	cfg.APIKeyEncrypted = ""
+}
+
+// SetAPIKey stores value as the API key for backend in the named profile, or
+// at the top level when profile is "", replacing any other key source there
+func (cfg *Config) SetAPIKey(profile, backend, value string) error {
+	if profile == "" {
+		cfg.ClearAPIKey()
+		switch backend {
+		case BackendPlain:
+			cfg.APIKey = value
+		case BackendCommand:
+			cfg.APIKeyCommand = value
+		case BackendFile:
+			cfg.APIKeyFile = value
+		case BackendEncrypted:
+			cfg.APIKeyEncrypted = value
+		default:
+			return fmt.Errorf("unknown backend %q", backend)
+		}
+		return nil
+	}
+
+	p, ok := cfg.Profiles[profile]
+	if !ok {
+		return fmt.Errorf("profile %s not found", profile)
+	}
+	var source **string
+	switch backend {
+	case BackendPlain:
+		source = &p.APIKey
+	case BackendCommand:
+		source = &p.APIKeyCommand
+	case BackendFile:
+		source = &p.APIKeyFile
+	case BackendEncrypted:
+		source = &p.APIKeyEncrypted
+	default:
+		return fmt.Errorf("unknown backend %q", backend)
+	}
+	p.APIKey, p.APIKeyCommand, p.APIKeyFile, p.APIKeyEncrypted = nil, nil, nil, nil
+	*source = &value
+	return nil
+}
+
+// ResolveAPIKey fills in cfg.APIKey from the configured secret backend
+// passphrase is only called when the key is stored encrypted
+func (cfg *Config) ResolveAPIKey(passphrase func() (string, error)) error {
+	switch cfg.APIKeyBackend() {
+	case BackendPlain:
+		return nil
+	case BackendCommand:
+		key, err := runKeyCommand(cfg.APIKeyCommand)
+		if err != nil {
+			return err
+		}
+		cfg.APIKey = key
+	case BackendFile:
+		data, err := os.ReadFile(ExpandHome(cfg.APIKeyFile))
+		if err != nil {
+			return fmt.Errorf("failed to read api_key_file: %w", err)
+		}
+		cfg.APIKey = strings.TrimSpace(string(data))
+	case BackendEncrypted:
+		pass, err := passphrase()
+		if err != nil {
+			return err
+		}
+		key, err := DecryptSecret(cfg.APIKeyEncrypted, pass)
+		if err != nil {
+			return err
+		}
+		cfg.APIKey = key
+	}
+
+	if cfg.APIKey == "" {
+		return ErrNoAPIKey
+	}
+	return nil
+}
+
+// runKeyCommand runs an api_key_command through the shell and returns its trimmed output
+// Stdin is not connected so a piped prompt is left for the command being run,
+// and stderr passes through for tools such as gpg that prompt there
+func runKeyCommand(command string) (string, error) {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stderr = os.Stderr
+
+	var stdout bytes.Buffer
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("api_key_command failed: %w", err)
+	}
+
+	// Tools like 'pass' print the secret on the first line
+	key, _, _ := strings.Cut(strings.TrimSpace(stdout.String()), "\n")
+	return strings.TrimSpace(key), nil
+}
+
+// WriteKeyFile stores a key in a file readable only by the current user
+func WriteKeyFile(path, key string) error {
+	path = ExpandHome(path)
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(key+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write key file: %w", err)
+	}
+	return nil
+}
+
+// DefaultKeyFilePath returns the api_key_file location used by 'config set-key'
+func DefaultKeyFilePath() string {
+	return filepath.Join(filepath.Dir(GetConfigPath()), "api_key")
+}
+
+// EncryptSecret seals secret with a key derived from passphrase
+// The result is "v1:<salt>:<nonce>:<ciphertext>" with base64 fields, using
+// PBKDF2-SHA256 for key derivation and AES-256-GCM for encryption
+func EncryptSecret(secret, passphrase string) (string, error) {
+	salt := make([]byte, kdfSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	gcm, err := newCipher(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nil, nonce, []byte(secret), []byte(encryptedVersion))
+
+	enc := base64.StdEncoding
+	return strings.Join([]string{
+		encryptedVersion,
+		enc.EncodeToString(salt),
+		enc.EncodeToString(nonce),
+		enc.EncodeToString(sealed),
+	}, ":"), nil
+}
+
+// DecryptSecret opens a value produced by EncryptSecret
+func DecryptSecret(blob, passphrase string) (string, error) {
+	parts := strings.Split(blob, ":")
+	if len(parts) != 4 || parts[0] != encryptedVersion {
+		return "", fmt.Errorf("unsupported api_key_encrypted format")
+	}
+
+	enc := base64.StdEncoding
+	salt, err1 := enc.DecodeString(parts[1])
+	nonce, err2 := enc.DecodeString(parts[2])
+	sealed, err3 := enc.DecodeString(parts[3])
+	if err := errors.Join(err1, err2, err3); err != nil {
+		return "", fmt.Errorf("invalid api_key_encrypted: %w", err)
+	}
+
+	gcm, err := newCipher(passphrase, salt)
+	if err != nil {
+		return "", err
+	}
+	if len(nonce) != gcm.NonceSize() {
+		return "", fmt.Errorf("invalid api_key_encrypted: bad nonce")
+	}
+
+	secret, err := gcm.Open(nil, nonce, sealed, []byte(encryptedVersion))
+	if err != nil {
+		return "", ErrWrongPassphrase
+	}
+	return string(secret), nil
+}
+
+// newCipher derives an AES-256-GCM cipher from a passphrase and salt
+func newCipher(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := pbkdf2.Key(sha256.New, passphrase, salt, kdfIterations, 32)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// ExpandHome replaces a leading ~ with the user's home directory
+func ExpandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
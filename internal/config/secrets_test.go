@@ -0,0 +1,135 @@
+package config
+
+import (
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfig makes content the user config file and runs the test from an
+// empty directory with no OPENROUTER_* variables or --profile set
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, "OPENROUTER_") {
+			t.Setenv(name, "")
+		}
+	}
+	SetProfile("")
+	t.Cleanup(func() { SetProfile("") })
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OPENROUTER_CONFIG", path)
+	return path
+}
+
+func TestEncryptSecretRoundTrip(t *testing.T) {
+	sealed, err := EncryptSecret("sk-or-v1-secret", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(sealed, "v1:") || strings.Contains(sealed, "sk-or-v1-secret") {
+		t.Errorf("sealed = %q", sealed)
+	}
+	if again, _ := EncryptSecret("sk-or-v1-secret", "hunter2"); again == sealed {
+		t.Error("encrypting twice gave the same output; salt or nonce is reused")
+	}
+
+	got, err := DecryptSecret(sealed, "hunter2")
+	if err != nil || got != "sk-or-v1-secret" {
+		t.Errorf("DecryptSecret = %q, %v", got, err)
+	}
+	if _, err := DecryptSecret(sealed, "hunter3"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Errorf("wrong passphrase: err = %v, want ErrWrongPassphrase", err)
+	}
+}
+
+func TestDecryptSecretCorrupt(t *testing.T) {
+	sealed, err := EncryptSecret("sk-or-v1-secret", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parts := strings.Split(sealed, ":")
+
+	// Flip a bit of the ciphertext so the authentication tag no longer matches
+	ciphertext, _ := base64.StdEncoding.DecodeString(parts[3])
+	ciphertext[0] ^= 1
+	tampered := strings.Join([]string{parts[0], parts[1], parts[2], base64.StdEncoding.EncodeToString(ciphertext)}, ":")
+
+	tests := []struct {
+		name string
+		blob string
+	}{
+		{"tampered ciphertext", tampered},
+		{"bad base64", "v1:" + parts[1] + ":" + parts[2] + ":not*base64"},
+		{"short nonce", strings.Join([]string{parts[0], parts[1], "AAAA", parts[3]}, ":")},
+		{"missing field", strings.Join(parts[:3], ":")},
+		{"unknown version", "v2:" + strings.Join(parts[1:], ":")},
+		{"plain key", "sk-or-v1-secret"},
+	}
+	for _, tt := range tests {
+		got, err := DecryptSecret(tt.blob, "hunter2")
+		if err == nil {
+			t.Errorf("%s: decrypted to %q", tt.name, got)
+		}
+	}
+}
+
+func TestProfileEncryptedKey(t *testing.T) {
+	sealed, err := EncryptSecret("sk-or-v1-team", "hunter2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeConfig(t, "api_key: sk-or-v1-personal\nprofiles:\n  team:\n    api_key_encrypted: \""+sealed+"\"\n")
+	SetProfile("team")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKeyBackend() != BackendEncrypted || cfg.Origin("api_key_encrypted") != "profile: team" {
+		t.Fatalf("backend = %q from %q", cfg.APIKeyBackend(), cfg.Origin("api_key_encrypted"))
+	}
+	if err := cfg.ResolveAPIKey(func() (string, error) { return "hunter2", nil }); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "sk-or-v1-team" {
+		t.Errorf("APIKey = %q, want the profile's key", cfg.APIKey)
+	}
+}
+
+func TestSetAPIKeyInProfile(t *testing.T) {
+	command := "pass show team"
+	cfg := &Config{
+		APIKey:   "sk-or-v1-personal",
+		Profiles: map[string]*Profile{"team": {APIKeyCommand: &command}},
+	}
+	if err := cfg.SetAPIKey("team", BackendEncrypted, "v1:sealed"); err != nil {
+		t.Fatal(err)
+	}
+	team := cfg.Profiles["team"]
+	if team.APIKeyCommand != nil || team.APIKeyEncrypted == nil || *team.APIKeyEncrypted != "v1:sealed" {
+		t.Errorf("profile = %+v", team)
+	}
+	if cfg.APIKey != "sk-or-v1-personal" {
+		t.Errorf("top-level key changed to %q", cfg.APIKey)
+	}
+
+	if err := cfg.SetAPIKey("", BackendFile, "/keys/openrouter"); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.APIKey != "" || cfg.APIKeyFile != "/keys/openrouter" {
+		t.Errorf("top level = %q, %q", cfg.APIKey, cfg.APIKeyFile)
+	}
+	if err := cfg.SetAPIKey("missing", BackendPlain, "sk"); err == nil {
+		t.Error("expected an error for a missing profile")
+	}
+}
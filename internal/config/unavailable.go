@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// now is the clock used for unavailable-model expiry
+var now = time.Now
+
+// UnavailableModel is an entry in unavailable_models
+// ID may be a glob such as "qwen/*:free", where * matches any characters
+// (including /) and ? matches one. Entries without a reason or expiry are
+// written as a plain string, so older files keep their layout.
+type UnavailableModel struct {
+	ID     string     `yaml:"id"`
+	Reason string     `yaml:"reason,omitempty"`
+	Until  *time.Time `yaml:"until,omitempty"`
+}
+
+// unavailableFields has UnavailableModel's fields without its YAML methods
+type unavailableFields UnavailableModel
+
+// UnmarshalYAML accepts either a model ID or a mapping with id, reason and until
+func (m *UnavailableModel) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		*m = UnavailableModel{ID: node.Value}
+		return nil
+	}
+	var fields unavailableFields
+	if err := node.Decode(&fields); err != nil {
+		return err
+	}
+	if fields.ID == "" {
+		return fmt.Errorf("line %d: unavailable_models entry needs an id", node.Line)
+	}
+	*m = UnavailableModel(fields)
+	return nil
+}
+
+// MarshalYAML writes a plain ID when there is no reason or expiry
+func (m UnavailableModel) MarshalYAML() (any, error) {
+	if m.Reason == "" && m.Until == nil {
+		return m.ID, nil
+	}
+	return unavailableFields(m), nil
+}
+
+// UnmarshalText parses an entry from 'config set' or the environment, which only carry IDs
+func (m *UnavailableModel) UnmarshalText(text []byte) error {
+	*m = UnavailableModel{ID: string(text)}
+	return nil
+}
+
+// MarshalText returns the entry's ID
+func (m UnavailableModel) MarshalText() ([]byte, error) {
+	return []byte(m.ID), nil
+}
+
+// IsGlob reports whether the entry's ID is a pattern
+func (m UnavailableModel) IsGlob() bool {
+	return strings.ContainsAny(m.ID, "*?")
+}
+
+// Matches reports whether modelID is covered by the entry
+func (m UnavailableModel) Matches(modelID string) bool {
+	if !m.IsGlob() {
+		return m.ID == modelID
+	}
+	return matchGlob([]rune(m.ID), []rune(modelID))
+}
+
+// matchGlob reports whether name matches pattern, where * matches any run of
+// characters and ? exactly one. After a mismatch it retries from the most
+// recent *, letting that * take one more character.
+func matchGlob(pattern, name []rune) bool {
+	p, n := 0, 0
+	star, next := -1, 0
+	for n < len(name) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, next = p, n
+			p++
+		case p < len(pattern) && (pattern[p] == '?' || pattern[p] == name[n]):
+			p++
+			n++
+		case star >= 0:
+			next++
+			p, n = star+1, next
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+// Expired reports whether the entry's expiry has passed
+func (m UnavailableModel) Expired() bool {
+	return m.Until != nil && !now().Before(*m.Until)
+}
+
+// UnavailableEntry returns the unexpired entry covering modelID, or nil
+func (cfg *Config) UnavailableEntry(modelID string) *UnavailableModel {
+	for i, m := range cfg.UnavailableModels {
+		if !m.Expired() && m.Matches(modelID) {
+			return &cfg.UnavailableModels[i]
+		}
+	}
+	return nil
+}
+
+// ParseExpiry parses a duration such as "12h", "7d" or "2w" into an expiry time
+// Days and weeks are added to today's date, so "7d" expires at this time next week
+func ParseExpiry(value string) (time.Time, error) {
+	if days, ok := parseDays(value); ok {
+		return now().AddDate(0, 0, days), nil
+	}
+	d, err := ParseDuration(value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return now().Add(d), nil
+}
+
+// PruneUnavailable removes expired entries, and entries for exact model IDs
+// that are not in catalog. Globs are kept since they may match future models.
+// It returns the removed entries.
+func (cfg *Config) PruneUnavailable(catalog []string) []UnavailableModel {
+	known := make(map[string]bool, len(catalog))
+	for _, id := range catalog {
+		known[id] = true
+	}
+
+	var kept, removed []UnavailableModel
+	for _, m := range cfg.UnavailableModels {
+		if m.Expired() || (!m.IsGlob() && !known[m.ID]) {
+			removed = append(removed, m)
+			continue
+		}
+		kept = append(kept, m)
+	}
+	cfg.UnavailableModels = kept
+	return removed
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUnavailableModelMatches(t *testing.T) {
+	tests := []struct {
+		id      string
+		modelID string
+		want    bool
+	}{
+		{"qwen/qwen-2:free", "qwen/qwen-2:free", true},
+		{"qwen/qwen-2:free", "qwen/qwen-2", false},
+		{"qwen/*:free", "qwen/qwen-2:free", true},
+		{"qwen/*:free", "qwen/qwen-2", false},
+		{"qwen/*", "qwen/", true},
+		{"*:free", "meta-llama/llama-3:free", true},
+		{"*/llama-*", "meta-llama/llama-3", true},
+		{"meta-*", "meta-llama/llama-3/extra", true}, // * crosses slashes
+		{"*llama*llama*", "meta-llama/llama-3", true},
+		{"*llama*llama*llama*", "meta-llama/llama-3", false},
+		{"gpt-?", "gpt-4", true},
+		{"gpt-?", "gpt-4o", false},
+		{"gpt-?o", "gpt-4o", true},
+		{"*", "", true},
+		{"?", "", false},
+		{"openai/gpt-4.?", "openai/gpt-4.1", true},
+		{"openai/gpt-4.?", "openai/gpt-4x1", false}, // . is literal
+		{"a+b/*", "aab/x", false},
+		{"模型/?", "模型/七", true},
+	}
+	for _, tt := range tests {
+		if got := (UnavailableModel{ID: tt.id}).Matches(tt.modelID); got != tt.want {
+			t.Errorf("%q.Matches(%q) = %v, want %v", tt.id, tt.modelID, got, tt.want)
+		}
+	}
+}
+
+func TestUnavailableEntry(t *testing.T) {
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	now = func() time.Time { return start }
+	defer func() { now = time.Now }()
+
+	until := start.Add(time.Hour)
+	cfg := &Config{UnavailableModels: []UnavailableModel{
+		{ID: "qwen/*:free", Until: &until},
+		{ID: "openai/gpt-4", Reason: "retired"},
+	}}
+	if m := cfg.UnavailableEntry("qwen/qwen-2:free"); m == nil || m.ID != "qwen/*:free" {
+		t.Errorf("entry = %+v, want the glob", m)
+	}
+	if m := cfg.UnavailableEntry("openai/gpt-4"); m == nil || m.Reason != "retired" {
+		t.Errorf("entry = %+v, want the exact ID", m)
+	}
+
+	now = func() time.Time { return until }
+	if m := cfg.UnavailableEntry("qwen/qwen-2:free"); m != nil {
+		t.Errorf("expired entry %+v still applies", m)
+	}
+}
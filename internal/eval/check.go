@@ -0,0 +1,254 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// CheckResult is the outcome of one expected property
+type CheckResult struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Check runs the checks that don't need a model: contains, not_contains,
+// regex and json_schema. The judge rubric is graded separately.
+func (e Expect) Check(response string) []CheckResult {
+	var results []CheckResult
+	fold := func(s string) string {
+		if e.IgnoreCase {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+
+	for _, want := range e.Contains {
+		passed := strings.Contains(fold(response), fold(want))
+		results = append(results, result(fmt.Sprintf("contains %q", want), passed, "not found in the response"))
+	}
+	for _, unwanted := range e.NotContains {
+		passed := !strings.Contains(fold(response), fold(unwanted))
+		results = append(results, result(fmt.Sprintf("not_contains %q", unwanted), passed, "found in the response"))
+	}
+	for _, pattern := range e.Regex {
+		if e.IgnoreCase {
+			pattern = "(?i)" + pattern
+		}
+		// Patterns were checked when the suite was loaded
+		passed := regexp.MustCompile(pattern).MatchString(response)
+		results = append(results, result(fmt.Sprintf("regex %q", pattern), passed, "no match in the response"))
+	}
+	if e.JSONSchema != nil {
+		results = append(results, checkJSONSchema(e.JSONSchema, response))
+	}
+	return results
+}
+
+func result(name string, passed bool, failure string) CheckResult {
+	r := CheckResult{Name: name, Passed: passed}
+	if !passed {
+		r.Detail = failure
+	}
+	return r
+}
+
+// checkJSONSchema checks that the response is JSON matching schema
+// A response wrapped in a Markdown code fence is unwrapped first.
+func checkJSONSchema(schema map[string]any, response string) CheckResult {
+	var value any
+	if err := json.Unmarshal([]byte(unfence(response)), &value); err != nil {
+		return CheckResult{Name: "json_schema", Detail: fmt.Sprintf("response is not JSON: %v", err)}
+	}
+	problems := validate(schema, value, "$")
+	if len(problems) > 0 {
+		return CheckResult{Name: "json_schema", Detail: strings.Join(problems, "; ")}
+	}
+	return CheckResult{Name: "json_schema", Passed: true}
+}
+
+// unfence returns the content of a response that is a single fenced code block
+func unfence(s string) string {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "```") || !strings.HasSuffix(s, "```") {
+		return s
+	}
+	body := strings.TrimSuffix(s, "```")
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 {
+		return strings.TrimSpace(body[newline+1:])
+	}
+	return s
+}
+
+// validate checks value against a subset of JSON Schema: type, enum, const,
+// properties, required, additionalProperties, items, minItems, maxItems,
+// minLength, maxLength, pattern, minimum, maximum and anyOf. Other keywords
+// are ignored.
+func validate(schema map[string]any, value any, path string) []string {
+	var problems []string
+	fail := func(format string, args ...any) {
+		problems = append(problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	if types, ok := schema["type"]; ok {
+		if !matchesType(types, value) {
+			fail("expected %v, got %s", types, typeName(value))
+			return problems
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, option := range enum {
+			if equalJSON(option, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("%v is not one of %v", value, enum)
+		}
+	}
+	if c, ok := schema["const"]; ok && !equalJSON(c, value) {
+		fail("expected %v, got %v", c, value)
+	}
+	if options, ok := schema["anyOf"].([]any); ok {
+		matched := false
+		for _, option := range options {
+			if sub, ok := option.(map[string]any); ok && len(validate(sub, value, path)) == 0 {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			fail("matches none of anyOf")
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]any:
+		properties, _ := schema["properties"].(map[string]any)
+		if required, ok := schema["required"].([]any); ok {
+			for _, name := range required {
+				if _, ok := v[fmt.Sprint(name)]; !ok {
+					fail("missing required property %q", name)
+				}
+			}
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if sub, ok := properties[name].(map[string]any); ok {
+				problems = append(problems, validate(sub, v[name], path+"."+name)...)
+				continue
+			}
+			switch extra := schema["additionalProperties"].(type) {
+			case bool:
+				if !extra {
+					fail("unexpected property %q", name)
+				}
+			case map[string]any:
+				problems = append(problems, validate(extra, v[name], path+"."+name)...)
+			}
+		}
+	case []any:
+		if n, ok := number(schema["minItems"]); ok && float64(len(v)) < n {
+			fail("has %d items, fewer than %v", len(v), n)
+		}
+		if n, ok := number(schema["maxItems"]); ok && float64(len(v)) > n {
+			fail("has %d items, more than %v", len(v), n)
+		}
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range v {
+				problems = append(problems, validate(items, item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case string:
+		length := float64(utf8.RuneCountInString(v))
+		if n, ok := number(schema["minLength"]); ok && length < n {
+			fail("is shorter than %v characters", n)
+		}
+		if n, ok := number(schema["maxLength"]); ok && length > n {
+			fail("is longer than %v characters", n)
+		}
+		if pattern, ok := schema["pattern"].(string); ok {
+			if re, err := regexp.Compile(pattern); err != nil {
+				fail("invalid pattern in schema: %v", err)
+			} else if !re.MatchString(v) {
+				fail("%q does not match %q", v, pattern)
+			}
+		}
+	case float64:
+		if n, ok := number(schema["minimum"]); ok && v < n {
+			fail("%v is less than %v", v, n)
+		}
+		if n, ok := number(schema["maximum"]); ok && v > n {
+			fail("%v is more than %v", v, n)
+		}
+	}
+	return problems
+}
+
+// matchesType reports whether value has the schema type, or one of a list
+func matchesType(types any, value any) bool {
+	if list, ok := types.([]any); ok {
+		for _, t := range list {
+			if matchesType(t, value) {
+				return true
+			}
+		}
+		return false
+	}
+	want := fmt.Sprint(types)
+	if want == "integer" {
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return want == typeName(value)
+}
+
+// typeName is the JSON Schema type of a decoded JSON value
+func typeName(value any) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	case map[string]any:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// number reads a schema number, which YAML may have decoded as an int
+func number(v any) (float64, bool) {
+	switch n := v.(type) {
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
+
+// equalJSON compares a schema value from YAML with a decoded JSON value
+func equalJSON(schemaValue, value any) bool {
+	if n, ok := number(schemaValue); ok {
+		v, ok := value.(float64)
+		return ok && v == n
+	}
+	return fmt.Sprint(schemaValue) == fmt.Sprint(value) && typeName(schemaValue) == typeName(value)
+}
@@ -0,0 +1,212 @@
+package eval
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/batch"
+)
+
+// judgeSystem instructs the judge model how to grade an answer
+const judgeSystem = `You grade an AI assistant's answer against a rubric.
+Reply with PASS or FAIL on the first line, then one sentence explaining why.`
+
+// Runner runs a suite's cases against each model and grades the answers
+type Runner struct {
+	Client      batch.Sender
+	Concurrency int
+	Retries     int
+	Backoff     time.Duration
+
+	// Request builds the request for an item; Item.Model is the model as
+	// named in the suite, or the judge model
+	Request func(batch.Item) (*api.ChatCompletionRequest, error)
+	// Cost prices a response, returning false when the price is unknown; optional
+	Cost func(model string, usage api.Usage) (float64, bool)
+	// Progress is called as each candidate answer arrives, before it is
+	// graded; optional
+	Progress func(done, total int, r CaseResult)
+	// Sleep and Now are passed to the batch runner; they default to the real clock
+	Sleep func(time.Duration)
+	Now   func() time.Time
+}
+
+// Run sends every case to every model, grades the answers and summarizes
+// them per model. judge is the model that grades rubrics; it may be empty
+// when no case has one.
+func (r *Runner) Run(suite *Suite, models []string, judge string) (*Report, error) {
+	if len(models) == 0 {
+		return nil, fmt.Errorf("no models to evaluate")
+	}
+	if judge == "" && suite.NeedsJudge() {
+		return nil, fmt.Errorf("the suite has judge rubrics but no judge model")
+	}
+
+	// Items are numbered so results can be matched to their case and model
+	var items []batch.Item
+	results := make([]CaseResult, 0, len(models)*len(suite.Cases))
+	for _, model := range models {
+		for _, c := range suite.Cases {
+			items = append(items, suite.item(strconv.Itoa(len(items)), model, c))
+			results = append(results, CaseResult{Case: c.ID, Model: model})
+		}
+	}
+
+	done := 0
+	err := r.batchRunner().Run(items, func(answer batch.Result) error {
+		i, _ := strconv.Atoi(answer.ID)
+		res := &results[i]
+		res.ResolvedModel = answer.Model
+		res.Response = answer.Response
+		res.Error = answer.Error
+		res.Usage = answer.Usage
+		res.Cost = answer.Cost
+		res.Cached = answer.Cached
+		res.DurationMS = answer.DurationMS
+		done++
+		if r.Progress != nil {
+			r.Progress(done, len(items), *res)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var judgeItems []batch.Item
+	for i := range results {
+		res := &results[i]
+		if res.Error != "" {
+			continue
+		}
+		expect := suite.Cases[i%len(suite.Cases)].Expect
+		res.Checks = expect.Check(res.Response)
+		if expect.Judge != "" {
+			judgeItems = append(judgeItems, judgeItem(strconv.Itoa(i), judge, items[i], expect.Judge, res.Response))
+		}
+	}
+
+	report := &Report{Suite: suite.Name, Judge: judge}
+	if len(judgeItems) > 0 {
+		err := r.batchRunner().Run(judgeItems, func(verdict batch.Result) error {
+			i, _ := strconv.Atoi(verdict.ID)
+			results[i].Checks = append(results[i].Checks, gradeVerdict(verdict))
+			if verdict.Cost != nil {
+				report.JudgeCost += *verdict.Cost
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for i := range results {
+		results[i].Passed = results[i].Error == "" && allPassed(results[i].Checks)
+	}
+	report.Results = results
+	report.Models = summarize(models, results)
+	return report, nil
+}
+
+func (r *Runner) batchRunner() *batch.Runner {
+	return &batch.Runner{
+		Client:      r.Client,
+		Concurrency: r.Concurrency,
+		Retries:     r.Retries,
+		Backoff:     r.Backoff,
+		Request:     r.Request,
+		Cost:        r.Cost,
+		Sleep:       r.Sleep,
+		Now:         r.Now,
+	}
+}
+
+// item builds the request item for a case, falling back to the suite's settings
+func (s *Suite) item(id, model string, c Case) batch.Item {
+	item := batch.Item{
+		ID:          id,
+		Model:       model,
+		Prompt:      c.Input,
+		System:      c.System,
+		Temperature: c.Temperature,
+		MaxTokens:   c.MaxTokens,
+	}
+	if item.System == "" {
+		item.System = s.System
+	}
+	if item.Temperature == nil {
+		item.Temperature = s.Temperature
+	}
+	if item.MaxTokens == nil {
+		item.MaxTokens = s.MaxTokens
+	}
+	return item
+}
+
+// judgeItem asks the judge model to grade response against rubric
+func judgeItem(id, judge string, asked batch.Item, rubric, response string) batch.Item {
+	var prompt strings.Builder
+	if asked.System != "" {
+		fmt.Fprintf(&prompt, "System prompt:\n%s\n\n", asked.System)
+	}
+	fmt.Fprintf(&prompt, "Question:\n%s\n\nAnswer:\n%s\n\nRubric:\n%s", asked.Prompt, response, rubric)
+
+	zero := 0.0
+	return batch.Item{
+		ID:          id,
+		Model:       judge,
+		System:      judgeSystem,
+		Prompt:      prompt.String(),
+		Temperature: &zero,
+	}
+}
+
+// gradeVerdict turns the judge's reply into a check result
+func gradeVerdict(verdict batch.Result) CheckResult {
+	if verdict.Error != "" {
+		return CheckResult{Name: "judge", Detail: "judge request failed: " + verdict.Error}
+	}
+	passed, reason, err := ParseVerdict(verdict.Response)
+	if err != nil {
+		return CheckResult{Name: "judge", Detail: err.Error()}
+	}
+	return CheckResult{Name: "judge", Passed: passed, Detail: reason}
+}
+
+// ParseVerdict reads PASS or FAIL from the first line of a judge's reply,
+// and the reason that follows it
+func ParseVerdict(reply string) (bool, string, error) {
+	first, rest, _ := strings.Cut(strings.TrimSpace(reply), "\n")
+	first = strings.TrimLeft(strings.TrimSpace(first), "*#` ")
+	reason := strings.TrimSpace(rest)
+
+	for _, v := range []struct {
+		word   string
+		passed bool
+	}{{"PASS", true}, {"FAIL", false}} {
+		if strings.HasPrefix(strings.ToUpper(first), v.word) {
+			if reason == "" {
+				// The reason may share the verdict's line, as in "PASS: correct"
+				reason = strings.Trim(first[len(v.word):], " *:.-`")
+			}
+			return v.passed, reason, nil
+		}
+	}
+	if runes := []rune(first); len(runes) > 80 {
+		first = string(runes[:77]) + "..."
+	}
+	return false, "", fmt.Errorf("judge reply has no PASS or FAIL verdict: %q", first)
+}
+
+func allPassed(checks []CheckResult) bool {
+	for _, c := range checks {
+		if !c.Passed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,113 @@
+package eval
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/batch"
+	"github.com/kdevrou/openrouter-cli/internal/mockserver"
+)
+
+const judgeModel = "openai/gpt-4o-mini"
+
+// newMock starts a mock API where the judge model always answers PASS
+// and other models echo the prompt
+func newMock(t *testing.T) (*mockserver.Server, *api.Client) {
+	t.Helper()
+	script, err := mockserver.ParseScript([]byte(`
+responses:
+  - model: ` + judgeModel + `
+    content: "PASS\nIt uses an analogy."
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mock, err := mockserver.New(mockserver.Options{Script: script})
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	client := api.NewClient(server.URL, "key", 10)
+	client.Limiter = nil
+	return mock, client
+}
+
+func newRunner(client *api.Client) *Runner {
+	return &Runner{
+		Client:      client,
+		Concurrency: 2,
+		Request: func(item batch.Item) (*api.ChatCompletionRequest, error) {
+			return &api.ChatCompletionRequest{
+				Model:       item.Model,
+				Messages:    item.ChatMessages(),
+				Temperature: item.Temperature,
+			}, nil
+		},
+	}
+}
+
+func TestRunSendsZeroTemperature(t *testing.T) {
+	mock, client := newMock(t)
+	suite, err := ParseSuite([]byte(`
+temperature: 0
+cases:
+  - id: recursion
+    input: Explain recursion to a child.
+    expect:
+      contains: recursion
+      judge: Uses a simple everyday analogy.
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	report, err := newRunner(client).Run(suite, []string{"mock/free"}, judgeModel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 1 || !report.Results[0].Passed {
+		t.Fatalf("results = %+v, want one passing result", report.Results)
+	}
+
+	requests := mock.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want the answer and the judge's grade", len(requests))
+	}
+	for _, req := range requests {
+		if req.Temperature == nil || *req.Temperature != 0 {
+			t.Errorf("%s request temperature = %v, want an explicit 0", req.Model, req.Temperature)
+		}
+	}
+	if requests[1].Model != judgeModel {
+		t.Errorf("second request went to %s, want the judge %s", requests[1].Model, judgeModel)
+	}
+}
+
+func TestJudgeTemperatureIgnoresSuite(t *testing.T) {
+	mock, client := newMock(t)
+	suite, err := ParseSuite([]byte(`
+temperature: 1.5
+cases:
+  - input: Explain recursion to a child.
+    expect:
+      judge: Uses a simple everyday analogy.
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := newRunner(client).Run(suite, []string{"mock/free"}, judgeModel); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, req := range mock.Requests() {
+		want := 1.5
+		if req.Model == judgeModel {
+			want = 0
+		}
+		if req.Temperature == nil || *req.Temperature != want {
+			t.Errorf("%s request temperature = %v, want %v", req.Model, req.Temperature, want)
+		}
+	}
+}
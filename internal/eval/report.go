@@ -0,0 +1,203 @@
+package eval
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// Report is the scored outcome of a suite run
+type Report struct {
+	Suite     string         `json:"suite,omitempty"`
+	Judge     string         `json:"judge,omitempty"`
+	JudgeCost float64        `json:"judge_cost"` // Dollars spent on grading
+	Models    []ModelSummary `json:"models"`
+	Results   []CaseResult   `json:"results"`
+}
+
+// ModelSummary is one model's pass rate, cost and latency across the suite
+type ModelSummary struct {
+	Model        string  `json:"model"`
+	Cases        int     `json:"cases"`
+	Passed       int     `json:"passed"`
+	Failed       int     `json:"failed"` // Answers that failed a check
+	Errors       int     `json:"errors"` // Requests that got no answer
+	PassRate     float64 `json:"pass_rate"`
+	Cost         float64 `json:"cost"` // Dollars, for answers whose price is known
+	TotalTokens  int     `json:"total_tokens"`
+	LatencyAvgMS int64   `json:"latency_avg_ms"`
+	LatencyP95MS int64   `json:"latency_p95_ms"`
+}
+
+// CaseResult is one model's answer to one case and how it was graded
+type CaseResult struct {
+	Case          string        `json:"case"`
+	Model         string        `json:"model"`
+	ResolvedModel string        `json:"resolved_model,omitempty"`
+	Passed        bool          `json:"passed"`
+	Checks        []CheckResult `json:"checks,omitempty"`
+	Response      string        `json:"response,omitempty"`
+	Error         string        `json:"error,omitempty"`
+	Usage         *api.Usage    `json:"usage,omitempty"`
+	Cost          *float64      `json:"cost,omitempty"`
+	Cached        bool          `json:"cached,omitempty"`
+	DurationMS    int64         `json:"duration_ms"`
+}
+
+// Failures describes the checks a result failed, or its error
+func (r CaseResult) Failures() []string {
+	if r.Error != "" {
+		return []string{"error: " + r.Error}
+	}
+	var failures []string
+	for _, c := range r.Checks {
+		if !c.Passed {
+			failures = append(failures, fmt.Sprintf("%s: %s", c.Name, c.Detail))
+		}
+	}
+	return failures
+}
+
+// summarize totals the results for each model, in the order given
+func summarize(models []string, results []CaseResult) []ModelSummary {
+	summaries := make([]ModelSummary, len(models))
+	for i, model := range models {
+		s := ModelSummary{Model: model}
+		var latencies []int64
+		var totalLatency int64
+		for _, r := range results {
+			if r.Model != model {
+				continue
+			}
+			s.Cases++
+			switch {
+			case r.Passed:
+				s.Passed++
+			case r.Error != "":
+				s.Errors++
+			default:
+				s.Failed++
+			}
+			if r.Cost != nil {
+				s.Cost += *r.Cost
+			}
+			if r.Usage != nil {
+				s.TotalTokens += r.Usage.TotalTokens
+			}
+			latencies = append(latencies, r.DurationMS)
+			totalLatency += r.DurationMS
+		}
+		if s.Cases > 0 {
+			s.PassRate = float64(s.Passed) / float64(s.Cases)
+			s.LatencyAvgMS = totalLatency / int64(s.Cases)
+			sort.Slice(latencies, func(a, b int) bool { return latencies[a] < latencies[b] })
+			s.LatencyP95MS = latencies[(len(latencies)*95+99)/100-1]
+		}
+		summaries[i] = s
+	}
+	return summaries
+}
+
+// BelowPassRate returns the models whose pass rate is under min
+func (r *Report) BelowPassRate(min float64) []ModelSummary {
+	var below []ModelSummary
+	for _, s := range r.Models {
+		if s.PassRate < min {
+			below = append(below, s)
+		}
+	}
+	return below
+}
+
+// JUnit XML elements; each model is a test suite and each case a test case
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Name    string       `xml:"name,attr,omitempty"`
+	Tests   int          `xml:"tests,attr"`
+	Fails   int          `xml:"failures,attr"`
+	Errors  int          `xml:"errors,attr"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name   string      `xml:"name,attr"`
+	Tests  int         `xml:"tests,attr"`
+	Fails  int         `xml:"failures,attr"`
+	Errors int         `xml:"errors,attr"`
+	Time   string      `xml:"time,attr"`
+	Cases  []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Body    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the report as JUnit XML for CI systems
+func (r *Report) WriteJUnit(w io.Writer) error {
+	doc := junitSuites{Name: r.Suite}
+	for _, s := range r.Models {
+		suite := junitSuite{Name: s.Model, Tests: s.Cases, Fails: s.Failed, Errors: s.Errors}
+		var total int64
+		for _, res := range r.Results {
+			if res.Model != s.Model {
+				continue
+			}
+			total += res.DurationMS
+			tc := junitCase{
+				Name:      res.Case,
+				ClassName: junitClass(r.Suite, s.Model),
+				Time:      seconds(res.DurationMS),
+				SystemOut: res.Response,
+			}
+			failures := strings.Join(res.Failures(), "\n")
+			switch {
+			case res.Error != "":
+				tc.Error = &junitProblem{Message: res.Error, Body: failures}
+			case !res.Passed:
+				tc.Failure = &junitProblem{Message: fmt.Sprintf("%d check(s) failed", len(res.Failures())), Body: failures}
+			}
+			suite.Cases = append(suite.Cases, tc)
+		}
+		suite.Time = seconds(total)
+		doc.Tests += suite.Tests
+		doc.Fails += suite.Fails
+		doc.Errors += suite.Errors
+		doc.Suites = append(doc.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func junitClass(suite, model string) string {
+	if suite == "" {
+		return model
+	}
+	return suite + "." + model
+}
+
+func seconds(ms int64) string {
+	return fmt.Sprintf("%.3f", float64(ms)/1000)
+}
@@ -0,0 +1,121 @@
+// Package eval runs test suites of prompts against several models and
+// scores the answers with string, regex, JSON schema and judge-model checks
+package eval
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Suite is a set of test cases run against each candidate model
+// Temperature, MaxTokens and System apply to every case that doesn't set
+// its own.
+type Suite struct {
+	Name        string   `yaml:"name,omitempty"`
+	Models      []string `yaml:"models"`
+	Judge       string   `yaml:"judge,omitempty"` // Model that grades rubric checks
+	System      string   `yaml:"system,omitempty"`
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	MaxTokens   *int     `yaml:"max_tokens,omitempty"`
+	Cases       []Case   `yaml:"cases"`
+}
+
+// Case is one prompt and the properties its answer must have
+type Case struct {
+	ID          string   `yaml:"id,omitempty"`
+	Input       string   `yaml:"input"`
+	System      string   `yaml:"system,omitempty"`
+	Temperature *float64 `yaml:"temperature,omitempty"`
+	MaxTokens   *int     `yaml:"max_tokens,omitempty"`
+	Expect      Expect   `yaml:"expect,omitempty"`
+}
+
+// Expect lists the checks an answer must pass; every one must hold
+type Expect struct {
+	Contains    StringList     `yaml:"contains,omitempty"`
+	NotContains StringList     `yaml:"not_contains,omitempty"`
+	Regex       StringList     `yaml:"regex,omitempty"`
+	JSONSchema  map[string]any `yaml:"json_schema,omitempty"`
+	Judge       string         `yaml:"judge,omitempty"` // Rubric for the judge model
+	IgnoreCase  bool           `yaml:"ignore_case,omitempty"`
+}
+
+// StringList is a YAML string or list of strings
+type StringList []string
+
+// UnmarshalYAML accepts a single string as a one-item list
+func (l *StringList) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		*l = StringList{value.Value}
+		return nil
+	}
+	var list []string
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// LoadSuite reads and checks a suite file
+func LoadSuite(path string) (*Suite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read suite: %w", err)
+	}
+	return ParseSuite(data)
+}
+
+// ParseSuite parses a suite, numbering cases without an ID, and reports
+// every problem found
+func ParseSuite(data []byte) (*Suite, error) {
+	var suite Suite
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&suite); err != nil {
+		return nil, fmt.Errorf("invalid suite: %w", err)
+	}
+
+	var problems []string
+	seen := make(map[string]bool)
+	for i := range suite.Cases {
+		c := &suite.Cases[i]
+		if c.ID == "" {
+			c.ID = "case-" + strconv.Itoa(i+1)
+		}
+		if seen[c.ID] {
+			problems = append(problems, fmt.Sprintf("case %s: id is used more than once", c.ID))
+		}
+		seen[c.ID] = true
+		if strings.TrimSpace(c.Input) == "" {
+			problems = append(problems, fmt.Sprintf("case %s: needs an input", c.ID))
+		}
+		for _, pattern := range c.Expect.Regex {
+			if _, err := regexp.Compile(pattern); err != nil {
+				problems = append(problems, fmt.Sprintf("case %s: invalid regex: %v", c.ID, err))
+			}
+		}
+	}
+	if len(suite.Cases) == 0 {
+		problems = append(problems, "no cases")
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid suite:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return &suite, nil
+}
+
+// NeedsJudge reports whether any case has a rubric for the judge model
+func (s *Suite) NeedsJudge() bool {
+	for _, c := range s.Cases {
+		if c.Expect.Judge != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package markdown
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CodeBlock is a fenced code block extracted from a markdown document
+type CodeBlock struct {
+	Lang     string // Language from the info string, e.g. "go"
+	Filename string // File name hint from the info string or a "file:" comment
+	Content  string
+}
+
+// fileHintRe matches a leading comment such as "// file: main.go" or "# file: app.py"
+var fileHintRe = regexp.MustCompile(`^\s*(?://|#|--|;|/\*|<!--)\s*(?:file(?:name)?|path)\s*:\s*(\S+?)\s*(?:\*/|-->)?\s*$`)
+
+// langAliases maps alternative fence languages to a canonical name
+var langAliases = map[string]string{
+	"golang": "go",
+	"py":     "python",
+	"js":     "javascript",
+	"ts":     "typescript",
+	"rs":     "rust",
+	"sh":     "bash",
+	"shell":  "bash",
+	"zsh":    "bash",
+	"yml":    "yaml",
+	"c++":    "cpp",
+	"rb":     "ruby",
+	"md":     "markdown",
+}
+
+// langExtensions maps canonical languages to file extensions
+var langExtensions = map[string]string{
+	"go": "go", "python": "py", "javascript": "js", "typescript": "ts", "rust": "rs",
+	"bash": "sh", "c": "c", "cpp": "cpp", "java": "java", "kotlin": "kt", "ruby": "rb",
+	"json": "json", "yaml": "yaml", "toml": "toml", "sql": "sql", "html": "html",
+	"css": "css", "markdown": "md", "dockerfile": "Dockerfile", "makefile": "Makefile",
+}
+
+// CanonicalLang normalizes a fence language so aliases such as "golang" and "go" compare equal
+func CanonicalLang(lang string) string {
+	lang = strings.ToLower(strings.TrimSpace(lang))
+	if canonical, ok := langAliases[lang]; ok {
+		return canonical
+	}
+	return lang
+}
+
+// LangForFile guesses a fence language from a file name, or returns ""
+func LangForFile(name string) string {
+	base := filepath.Base(name)
+	for lang, ext := range langExtensions {
+		if ext == base {
+			return lang
+		}
+	}
+	ext := strings.TrimPrefix(filepath.Ext(base), ".")
+	if ext == "" {
+		return ""
+	}
+	ext = CanonicalLang(ext)
+	if _, ok := langExtensions[ext]; ok {
+		return ext
+	}
+	for lang, e := range langExtensions {
+		if e == ext {
+			return lang
+		}
+	}
+	return ""
+}
+
+// Extension returns the file extension for the block's language, or "txt"
+func (b CodeBlock) Extension() string {
+	if ext, ok := langExtensions[CanonicalLang(b.Lang)]; ok {
+		return ext
+	}
+	return "txt"
+}
+
+// ExtractCodeBlocks returns the fenced code blocks in a markdown document
+// An unterminated block at the end of the document is still returned
+func ExtractCodeBlocks(text string) []CodeBlock {
+	var blocks []CodeBlock
+	var current *CodeBlock
+	var fence string
+	var lines []string
+
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimRight(line, "\r")
+
+		if current == nil {
+			m := fenceRe.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			fence = m[1]
+			lang, filename := parseInfoString(strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), fence[:1])))
+			current = &CodeBlock{Lang: lang, Filename: filename}
+			lines = nil
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			blocks = append(blocks, finishBlock(current, lines))
+			current = nil
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if current != nil {
+		blocks = append(blocks, finishBlock(current, lines))
+	}
+	return blocks
+}
+
+// finishBlock sets the content of a block and looks for a file name hint
+func finishBlock(block *CodeBlock, lines []string) CodeBlock {
+	if block.Filename == "" && len(lines) > 0 {
+		if m := fileHintRe.FindStringSubmatch(lines[0]); m != nil {
+			block.Filename = m[1]
+		}
+	}
+	block.Content = strings.Join(lines, "\n")
+	if block.Content != "" {
+		block.Content += "\n"
+	}
+	return *block
+}
+
+// parseInfoString splits a fence info string into a language and file name
+// Supported forms: "go", "go main.go", "go title=main.go", "go:main.go" and "main.go"
+func parseInfoString(info string) (string, string) {
+	fields := strings.Fields(info)
+	if len(fields) == 0 {
+		return "", ""
+	}
+
+	lang := fields[0]
+	filename := ""
+	if i := strings.IndexByte(lang, ':'); i > 0 {
+		lang, filename = lang[:i], lang[i+1:]
+	}
+
+	for _, field := range fields[1:] {
+		if k, v, ok := strings.Cut(field, "="); ok {
+			if k == "file" || k == "filename" || k == "title" || k == "path" {
+				filename = strings.Trim(v, `"'`)
+			}
+		} else if filename == "" && looksLikePath(field) {
+			filename = field
+		}
+	}
+
+	// A bare file name such as "main.go" doubles as the language
+	if filename == "" && looksLikePath(lang) {
+		filename = lang
+		lang = ""
+		if i := strings.LastIndexByte(filename, '.'); i >= 0 {
+			lang = filename[i+1:]
+		}
+	}
+
+	return lang, filename
+}
+
+// looksLikePath reports whether s looks like a file name rather than an attribute
+func looksLikePath(s string) bool {
+	return strings.ContainsAny(s, "./") && !strings.HasPrefix(s, "{")
+}
+
+// DefaultFilename returns a file name for the n-th block (1-based) when it has no hint
+func (b CodeBlock) DefaultFilename(n int) string {
+	return fmt.Sprintf("snippet-%d.%s", n, b.Extension())
+}
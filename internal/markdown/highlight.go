@@ -0,0 +1,220 @@
+package markdown
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+var (
+	keywordStyle = color.New(color.FgMagenta)
+	stringStyle  = color.New(color.FgGreen)
+	commentStyle = color.New(color.FgHiBlack)
+	numberStyle  = color.New(color.FgCyan)
+)
+
+// language describes the lexical rules used to highlight a fenced code block
+type language struct {
+	keywords     map[string]bool
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+}
+
+// words builds a keyword set from a whitespace-separated list
+func words(s string) map[string]bool {
+	m := make(map[string]bool)
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	goLang = &language{
+		keywords: words(`break case chan const continue default defer else fallthrough for func go goto if
+			import interface map package range return select struct switch type var
+			true false nil iota bool byte rune string error int int8 int16 int32 int64
+			uint uint8 uint16 uint32 uint64 uintptr float32 float64 any make new len cap append panic recover`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	pythonLang = &language{
+		keywords: words(`and as assert async await break class continue def del elif else except finally
+			for from global if import in is lambda nonlocal not or pass raise return try while with yield
+			None True False self print`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	jsLang = &language{
+		keywords: words(`async await break case catch class const continue debugger default delete do else
+			export extends finally for function if import in instanceof let new of return super switch
+			this throw try typeof var void while with yield true false null undefined
+			interface type enum implements private public protected readonly string number boolean`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'`",
+	}
+	rustLang = &language{
+		keywords: words(`as async await break const continue crate dyn else enum extern false fn for if impl
+			in let loop match mod move mut pub ref return self Self static struct super trait true type
+			unsafe use where while Some None Ok Err i8 i16 i32 i64 u8 u16 u32 u64 usize isize f32 f64
+			bool str String Vec Option Result`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"",
+	}
+	cLang = &language{
+		keywords: words(`auto break case catch char class const continue default delete do double else enum
+			extern final float for goto if import int long namespace new null nullptr package private
+			protected public return short signed sizeof static struct switch template this throw
+			try typedef union unsigned using var virtual void volatile while true false boolean
+			string val fun func let override`),
+		lineComments: []string{"//"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "\"'",
+	}
+	shellLang = &language{
+		keywords: words(`if then else elif fi case esac for while until do done in function return
+			local export set unset echo exit source alias cd`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+	sqlLang = &language{
+		keywords: words(`select from where and or not insert into values update set delete create table
+			drop alter index join left right inner outer on group by order having limit offset as
+			distinct union all null is in like between case when then else end primary key
+			SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE DROP ALTER
+			INDEX JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT OFFSET AS DISTINCT UNION
+			ALL NULL IS IN LIKE BETWEEN CASE WHEN THEN ELSE END PRIMARY KEY`),
+		lineComments: []string{"--"},
+		blockComment: [2]string{"/*", "*/"},
+		quotes:       "'\"",
+	}
+	dataLang = &language{
+		keywords:     words(`true false null yes no`),
+		lineComments: []string{"#"},
+		quotes:       "\"'",
+	}
+)
+
+// languages maps fence info strings to their lexical rules
+var languages = map[string]*language{
+	"go": goLang, "golang": goLang,
+	"python": pythonLang, "py": pythonLang,
+	"javascript": jsLang, "js": jsLang, "jsx": jsLang, "typescript": jsLang, "ts": jsLang, "tsx": jsLang,
+	"rust": rustLang, "rs": rustLang,
+	"c": cLang, "cpp": cLang, "c++": cLang, "h": cLang, "java": cLang, "kotlin": cLang,
+	"cs": cLang, "csharp": cLang, "swift": cLang, "scala": cLang,
+	"sh": shellLang, "bash": shellLang, "zsh": shellLang, "shell": shellLang, "console": shellLang,
+	"sql":  sqlLang,
+	"json": dataLang, "yaml": dataLang, "yml": dataLang, "toml": dataLang,
+}
+
+// highlighter colors the lines of one fenced code block
+// Block comments can span lines, so it carries state between calls
+type highlighter struct {
+	lang           *language
+	inBlockComment bool
+}
+
+// newHighlighter returns a highlighter for a fence info string such as "go"
+func newHighlighter(info string) *highlighter {
+	return &highlighter{lang: languages[strings.ToLower(info)]}
+}
+
+// line returns line with ANSI syntax highlighting applied
+func (h *highlighter) line(line string) string {
+	if h.lang == nil {
+		return line
+	}
+	lang := h.lang
+
+	var sb strings.Builder
+	for i := 0; i < len(line); {
+		rest := line[i:]
+
+		if h.inBlockComment {
+			end := strings.Index(rest, lang.blockComment[1])
+			if end < 0 {
+				sb.WriteString(commentStyle.Sprint(rest))
+				return sb.String()
+			}
+			end += len(lang.blockComment[1])
+			sb.WriteString(commentStyle.Sprint(rest[:end]))
+			h.inBlockComment = false
+			i += end
+			continue
+		}
+
+		if lang.blockComment[0] != "" && strings.HasPrefix(rest, lang.blockComment[0]) {
+			h.inBlockComment = true
+			sb.WriteString(commentStyle.Sprint(lang.blockComment[0]))
+			i += len(lang.blockComment[0])
+			continue
+		}
+
+		if hasAnyPrefix(rest, lang.lineComments) {
+			sb.WriteString(commentStyle.Sprint(rest))
+			return sb.String()
+		}
+
+		c := rest[0]
+		switch {
+		case strings.IndexByte(lang.quotes, c) >= 0:
+			end := 1
+			for end < len(rest) && rest[end] != c {
+				if rest[end] == '\\' && c != '`' {
+					end++
+				}
+				end++
+			}
+			if end < len(rest) {
+				end++
+			} else {
+				end = len(rest)
+			}
+			sb.WriteString(stringStyle.Sprint(rest[:end]))
+			i += end
+
+		case c >= '0' && c <= '9':
+			end := 1
+			for end < len(rest) && (isWordByte(rest[end]) || rest[end] == '.') {
+				end++
+			}
+			sb.WriteString(numberStyle.Sprint(rest[:end]))
+			i += end
+
+		case isWordByte(c):
+			end := 1
+			for end < len(rest) && isWordByte(rest[end]) {
+				end++
+			}
+			word := rest[:end]
+			if lang.keywords[word] {
+				sb.WriteString(keywordStyle.Sprint(word))
+			} else {
+				sb.WriteString(word)
+			}
+			i += end
+
+		default:
+			_, size := utf8.DecodeRuneInString(rest)
+			sb.WriteString(rest[:size])
+			i += size
+		}
+	}
+	return sb.String()
+}
+
+// hasAnyPrefix reports whether s starts with any of the prefixes
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, p := range prefixes {
+		if strings.HasPrefix(s, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,175 @@
+package markdown
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/fatih/color"
+)
+
+var (
+	boldStyle   = color.New(color.Bold)
+	italicStyle = color.New(color.Italic)
+	strikeStyle = color.New(color.CrossedOut)
+	codeStyle   = color.New(color.FgYellow)
+	linkStyle   = color.New(color.Underline, color.FgBlue)
+	urlStyle    = color.New(color.FgHiBlack)
+
+	ansiRe = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+// renderInline styles emphasis, code spans and links within a line
+func renderInline(text string) string {
+	var sb strings.Builder
+	for i := 0; i < len(text); {
+		rest := text[i:]
+
+		switch {
+		case rest[0] == '\\' && len(rest) > 1 && unicode.IsPunct(rune(rest[1])):
+			sb.WriteByte(rest[1])
+			i += 2
+			continue
+
+		case rest[0] == '`':
+			// Code spans may use several backticks so the content can contain one
+			ticks := len(rest) - len(strings.TrimLeft(rest, "`"))
+			delim := rest[:ticks]
+			if end := strings.Index(rest[ticks:], delim); end >= 0 {
+				code := strings.TrimSpace(rest[ticks : ticks+end])
+				sb.WriteString(codeStyle.Sprint(code))
+				i += 2*ticks + end
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**") || strings.HasPrefix(rest, "__"):
+			if inner, n, ok := delimited(text, i, rest[:2]); ok {
+				sb.WriteString(boldStyle.Sprint(renderInline(inner)))
+				i += n
+				continue
+			}
+
+		case strings.HasPrefix(rest, "~~"):
+			if inner, n, ok := delimited(text, i, "~~"); ok {
+				sb.WriteString(strikeStyle.Sprint(renderInline(inner)))
+				i += n
+				continue
+			}
+
+		case rest[0] == '*' || rest[0] == '_':
+			if inner, n, ok := delimited(text, i, rest[:1]); ok {
+				sb.WriteString(italicStyle.Sprint(renderInline(inner)))
+				i += n
+				continue
+			}
+
+		case rest[0] == '[':
+			if label, url, n, ok := link(rest); ok {
+				sb.WriteString(linkStyle.Sprint(renderInline(label)))
+				if url != label {
+					sb.WriteString(urlStyle.Sprint(" (" + url + ")"))
+				}
+				i += n
+				continue
+			}
+		}
+
+		_, size := utf8.DecodeRuneInString(rest)
+		sb.WriteString(rest[:size])
+		i += size
+	}
+	return sb.String()
+}
+
+// delimited finds the span of text[i:] enclosed by delim, returning the inner
+// text and the total length consumed
+// Underscore emphasis must sit on word boundaries so snake_case is left alone
+func delimited(text string, i int, delim string) (string, int, bool) {
+	start := i + len(delim)
+	if start >= len(text) || text[start] == ' ' {
+		return "", 0, false
+	}
+	if delim[0] == '_' && i > 0 && isWordByte(text[i-1]) {
+		return "", 0, false
+	}
+
+	for j := start + 1; j+len(delim) <= len(text); j++ {
+		if text[j:j+len(delim)] != delim || text[j-1] == ' ' || text[j-1] == '\\' {
+			continue
+		}
+		end := j + len(delim)
+		if delim[0] == '_' && end < len(text) && isWordByte(text[end]) {
+			continue
+		}
+		// A single * or _ must not match the first half of a double delimiter
+		if len(delim) == 1 && end < len(text) && text[end] == delim[0] {
+			j++
+			continue
+		}
+		return text[start:j], end - i, true
+	}
+	return "", 0, false
+}
+
+// link parses [label](url) at the start of text
+func link(text string) (string, string, int, bool) {
+	closeLabel := strings.Index(text, "](")
+	if closeLabel < 0 {
+		return "", "", 0, false
+	}
+	closeURL := strings.IndexByte(text[closeLabel:], ')')
+	if closeURL < 0 {
+		return "", "", 0, false
+	}
+	label := text[1:closeLabel]
+	url := text[closeLabel+2 : closeLabel+closeURL]
+	if strings.ContainsAny(url, " \t") {
+		// Drop an optional "title" after the URL
+		url = strings.Fields(url)[0]
+	}
+	return label, url, closeLabel + closeURL + 1, true
+}
+
+// isWordByte reports whether b is an ASCII letter, digit or underscore
+func isWordByte(b byte) bool {
+	return b == '_' || b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// stripInline removes inline markup, keeping only the text
+func stripInline(text string) string {
+	return ansiRe.ReplaceAllString(renderInline(text), "")
+}
+
+// visibleWidth returns the number of columns s occupies, ignoring ANSI escapes
+func visibleWidth(s string) int {
+	return utf8.RuneCountInString(ansiRe.ReplaceAllString(s, ""))
+}
+
+// wrap word-wraps styled text to width visible columns
+func wrap(text string, width int) []string {
+	if width <= 0 || visibleWidth(text) <= width {
+		return []string{text}
+	}
+
+	var lines []string
+	var current string
+	currentWidth := 0
+	for _, word := range strings.Fields(text) {
+		w := visibleWidth(word)
+		if currentWidth > 0 && currentWidth+1+w > width {
+			lines = append(lines, current)
+			current, currentWidth = "", 0
+		}
+		if currentWidth > 0 {
+			current += " "
+			currentWidth++
+		}
+		current += word
+		currentWidth += w
+	}
+	if current != "" {
+		lines = append(lines, current)
+	}
+	return lines
+}
@@ -0,0 +1,328 @@
+package markdown
+
+import (
+	"bytes"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+var (
+	headingRe    = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	listItemRe   = regexp.MustCompile(`^(\s*)([-*+]|\d+[.)])\s+(.*)$`)
+	taskRe       = regexp.MustCompile(`^\[([ xX])\]\s+(.*)$`)
+	ruleRe       = regexp.MustCompile(`^\s{0,3}([-*_])(\s*[-*_]){2,}\s*$`)
+	fenceRe      = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([^`\\s]*)")
+	quoteRe      = regexp.MustCompile(`^\s*((?:>\s?)+)(.*)$`)
+	tableAlignRe = regexp.MustCompile(`^:?-+:?$`)
+)
+
+var (
+	h1Style     = color.New(color.Bold, color.Underline, color.FgMagenta)
+	h2Style     = color.New(color.Bold, color.FgMagenta)
+	hStyle      = color.New(color.Bold, color.FgCyan)
+	bulletStyle = color.New(color.FgCyan)
+	quoteStyle  = color.New(color.FgHiBlack)
+	ruleStyle   = color.New(color.FgHiBlack)
+	fenceStyle  = color.New(color.FgHiBlack)
+	tableStyle  = color.New(color.FgHiBlack)
+	headerStyle = color.New(color.Bold)
+)
+
+// Renderer converts markdown to ANSI-styled terminal output
+// Text is rendered line by line as it is written, so a Renderer can sit
+// directly behind a streamed response. Tables are buffered until their last
+// row arrives so columns can be aligned. Call Close to flush the final line.
+type Renderer struct {
+	out   io.Writer
+	width int
+
+	pending []byte // Incomplete line waiting for its newline
+
+	// Fenced code block state
+	inCode    bool
+	fence     string
+	highlight *highlighter
+
+	table [][]string // Buffered table rows
+}
+
+// NewRenderer returns a Renderer writing to w, wrapping text at width columns
+func NewRenderer(w io.Writer, width int) *Renderer {
+	return &Renderer{out: w, width: width}
+}
+
+// Render renders a complete markdown document
+func Render(text string, width int) string {
+	var buf bytes.Buffer
+	r := NewRenderer(&buf, width)
+	r.Write([]byte(text))
+	r.Close()
+	return buf.String()
+}
+
+// Write renders every complete line in p and buffers the remainder
+func (r *Renderer) Write(p []byte) (int, error) {
+	r.pending = append(r.pending, p...)
+	for {
+		i := bytes.IndexByte(r.pending, '\n')
+		if i < 0 {
+			break
+		}
+		line := strings.TrimRight(string(r.pending[:i]), "\r")
+		r.pending = r.pending[i+1:]
+		if err := r.renderLine(line); err != nil {
+			return len(p), err
+		}
+	}
+	return len(p), nil
+}
+
+// Close renders any buffered partial line and table
+func (r *Renderer) Close() error {
+	if len(r.pending) > 0 {
+		line := string(r.pending)
+		r.pending = nil
+		if err := r.renderLine(line); err != nil {
+			return err
+		}
+	}
+	return r.flushTable()
+}
+
+// renderLine renders a single line of markdown
+func (r *Renderer) renderLine(line string) error {
+	if r.inCode {
+		if strings.HasPrefix(strings.TrimSpace(line), r.fence) &&
+			strings.Trim(strings.TrimSpace(line), r.fence[:1]) == "" {
+			r.inCode = false
+			return r.emit(fenceStyle.Sprint("  " + strings.Repeat("─", 3)))
+		}
+		return r.emit("  " + r.highlight.line(line))
+	}
+
+	// Table rows are buffered until a non-table line ends the table
+	trimmed := strings.TrimSpace(line)
+	if strings.HasPrefix(trimmed, "|") {
+		r.table = append(r.table, splitTableRow(trimmed))
+		return nil
+	}
+	if err := r.flushTable(); err != nil {
+		return err
+	}
+
+	if m := fenceRe.FindStringSubmatch(line); m != nil {
+		r.inCode = true
+		r.fence = m[1]
+		r.highlight = newHighlighter(m[2])
+		label := strings.Repeat("─", 3)
+		if m[2] != "" {
+			label += " " + m[2]
+		}
+		return r.emit(fenceStyle.Sprint("  " + label))
+	}
+
+	if trimmed == "" {
+		return r.emit("")
+	}
+
+	if m := headingRe.FindStringSubmatch(line); m != nil {
+		style := hStyle
+		switch len(m[1]) {
+		case 1:
+			style = h1Style
+		case 2:
+			style = h2Style
+		}
+		return r.emit(style.Sprint(stripInline(m[2])))
+	}
+
+	if ruleRe.MatchString(line) {
+		return r.emit(ruleStyle.Sprint(strings.Repeat("─", r.ruleWidth())))
+	}
+
+	if m := quoteRe.FindStringSubmatch(line); m != nil {
+		depth := strings.Count(m[1], ">")
+		prefix := quoteStyle.Sprint(strings.Repeat("│ ", depth))
+		return r.emitWrapped(quoteStyle.Sprint(renderInline(m[2])), prefix, prefix, 2*depth)
+	}
+
+	if m := listItemRe.FindStringSubmatch(line); m != nil {
+		indent := m[1]
+		marker := m[2]
+		text := m[3]
+		if marker == "-" || marker == "*" || marker == "+" {
+			marker = "•"
+			if t := taskRe.FindStringSubmatch(text); t != nil {
+				marker = "☐"
+				if t[1] != " " {
+					marker = "☑"
+				}
+				text = t[2]
+			}
+		}
+		first := indent + bulletStyle.Sprint(marker) + " "
+		hanging := indent + strings.Repeat(" ", len([]rune(marker))+1)
+		return r.emitWrapped(renderInline(text), first, hanging, len([]rune(hanging)))
+	}
+
+	return r.emitWrapped(renderInline(line), "", "", 0)
+}
+
+// emit writes a rendered line
+func (r *Renderer) emit(line string) error {
+	_, err := io.WriteString(r.out, line+"\n")
+	return err
+}
+
+// emitWrapped word-wraps styled text, starting the first line with first and
+// continuation lines with hanging; indent is the visible width of the prefixes
+func (r *Renderer) emitWrapped(text, first, hanging string, indent int) error {
+	lines := wrap(text, r.width-indent)
+	for i, line := range lines {
+		prefix := hanging
+		if i == 0 {
+			prefix = first
+		}
+		if err := r.emit(prefix + line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ruleWidth is the width of a horizontal rule
+func (r *Renderer) ruleWidth() int {
+	if r.width <= 0 || r.width > 80 {
+		return 80
+	}
+	return r.width
+}
+
+// flushTable renders any buffered table rows with aligned columns
+func (r *Renderer) flushTable() error {
+	if len(r.table) == 0 {
+		return nil
+	}
+	rows := r.table
+	r.table = nil
+
+	// A separator row after the first row marks it as a header and sets alignment
+	var aligns []string
+	hasHeader := false
+	if len(rows) > 1 && isAlignRow(rows[1]) {
+		aligns = rows[1]
+		hasHeader = true
+		rows = append(rows[:1:1], rows[2:]...)
+	}
+
+	cols := 0
+	for _, row := range rows {
+		if len(row) > cols {
+			cols = len(row)
+		}
+	}
+
+	cells := make([][]string, len(rows))
+	widths := make([]int, cols)
+	for i, row := range rows {
+		cells[i] = make([]string, cols)
+		for j := 0; j < cols; j++ {
+			if j < len(row) {
+				cells[i][j] = renderInline(row[j])
+			}
+			if w := visibleWidth(cells[i][j]); w > widths[j] {
+				widths[j] = w
+			}
+		}
+	}
+
+	bar := tableStyle.Sprint(" │ ")
+	for i, row := range cells {
+		parts := make([]string, cols)
+		for j, cell := range row {
+			if i == 0 && hasHeader {
+				cell = headerStyle.Sprint(cell)
+			}
+			align := ""
+			if j < len(aligns) {
+				align = aligns[j]
+			}
+			parts[j] = pad(cell, widths[j], align)
+		}
+		if err := r.emit(strings.TrimRight(strings.Join(parts, bar), " ")); err != nil {
+			return err
+		}
+
+		if i == 0 && hasHeader {
+			seps := make([]string, cols)
+			for j, w := range widths {
+				seps[j] = strings.Repeat("─", w)
+			}
+			if err := r.emit(tableStyle.Sprint(strings.Join(seps, "─┼─"))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// splitTableRow splits a "| a | b |" row into trimmed cells
+func splitTableRow(line string) []string {
+	line = strings.TrimPrefix(line, "|")
+	if strings.HasSuffix(line, "|") && !strings.HasSuffix(line, `\|`) {
+		line = line[:len(line)-1]
+	}
+
+	var cells []string
+	var cell strings.Builder
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			if c != '|' {
+				cell.WriteRune('\\')
+			}
+			cell.WriteRune(c)
+			escaped = false
+		case c == '\\':
+			escaped = true
+		case c == '|':
+			cells = append(cells, strings.TrimSpace(cell.String()))
+			cell.Reset()
+		default:
+			cell.WriteRune(c)
+		}
+	}
+	return append(cells, strings.TrimSpace(cell.String()))
+}
+
+// isAlignRow reports whether a row is a header separator such as |---|:-:|
+func isAlignRow(row []string) bool {
+	for _, cell := range row {
+		if !tableAlignRe.MatchString(strings.ReplaceAll(cell, " ", "")) {
+			return false
+		}
+	}
+	return len(row) > 0
+}
+
+// pad pads styled text to width visible columns using a table alignment cell
+func pad(text string, width int, align string) string {
+	gap := width - visibleWidth(text)
+	if gap <= 0 {
+		return text
+	}
+	left := strings.HasPrefix(align, ":")
+	right := strings.HasSuffix(align, ":")
+	switch {
+	case left && right:
+		return strings.Repeat(" ", gap/2) + text + strings.Repeat(" ", gap-gap/2)
+	case right:
+		return strings.Repeat(" ", gap) + text
+	default:
+		return text + strings.Repeat(" ", gap)
+	}
+}
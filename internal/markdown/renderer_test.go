@@ -0,0 +1,146 @@
+package markdown
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+)
+
+// setNoColor sets color.NoColor for the rest of the test
+func setNoColor(t *testing.T, noColor bool) {
+	saved := color.NoColor
+	color.NoColor = noColor
+	t.Cleanup(func() { color.NoColor = saved })
+}
+
+// newPlainRenderer returns a Renderer whose styles add no escape codes, so
+// tests can compare the layout alone
+func newPlainRenderer(t *testing.T) (*Renderer, *bytes.Buffer) {
+	setNoColor(t, true)
+	var out bytes.Buffer
+	return NewRenderer(&out, 80), &out
+}
+
+func write(t *testing.T, r *Renderer, chunk string) {
+	t.Helper()
+	if n, err := r.Write([]byte(chunk)); err != nil || n != len(chunk) {
+		t.Fatalf("Write(%q) = %d, %v", chunk, n, err)
+	}
+}
+
+func assertOutput(t *testing.T, out *bytes.Buffer, want ...string) {
+	t.Helper()
+	got := out.String()
+	expected := strings.Join(want, "\n")
+	if len(want) > 0 {
+		expected += "\n"
+	}
+	if got != expected {
+		t.Errorf("output:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestRendererCodeFenceAcrossWrites(t *testing.T) {
+	r, out := newPlainRenderer(t)
+	write(t, r, "Run this:\n``")
+	assertOutput(t, out, "Run this:")
+
+	// The fence only counts once its line is complete
+	write(t, r, "`go\n# not a heading\n**not bold**\n``")
+	write(t, r, "`\nDone\n")
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, out,
+		"Run this:",
+		"  ─── go",
+		"  # not a heading",
+		"  **not bold**",
+		"  ───",
+		"Done",
+	)
+}
+
+func TestRendererTableRowByRow(t *testing.T) {
+	r, out := newPlainRenderer(t)
+	for _, row := range []string{"| Fruit | Qty |\n", "|---|--:|\n", "| apple | 3 |\n", "| kiwi | 12 |\n"} {
+		write(t, r, row)
+		// Columns can't be aligned until the last row is known
+		assertOutput(t, out)
+	}
+
+	write(t, r, "\nThat's all.\n")
+	assertOutput(t, out,
+		"Fruit │ Qty",
+		"──────┼────",
+		"apple │   3",
+		"kiwi  │  12",
+		"",
+		"That's all.",
+	)
+}
+
+func TestRendererCloseFlushesLastLine(t *testing.T) {
+	r, out := newPlainRenderer(t)
+	write(t, r, "# Title\nSome **bold")
+	assertOutput(t, out, "Title")
+	write(t, r, "** text")
+	assertOutput(t, out, "Title")
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, out, "Title", "Some bold text")
+}
+
+func TestRendererCloseFlushesTable(t *testing.T) {
+	r, out := newPlainRenderer(t)
+	write(t, r, "| a | bb |\n| ccc | d |")
+	assertOutput(t, out)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assertOutput(t, out, "a   │ bb", "ccc │ d")
+}
+
+func TestRenderMatchesChunkedWrites(t *testing.T) {
+	doc := "## Steps\n\n1. Install\n- [x] done\n> quoted `code`\n\n```python\ndef f():\n    return 1\n```\n| x | y |\n|---|---|\n| 1 | 2 |\nend"
+	setNoColor(t, true)
+	want := Render(doc, 80)
+
+	// Any split of the input renders the same as the whole document
+	for size := 1; size <= 7; size++ {
+		r, out := newPlainRenderer(t)
+		for i := 0; i < len(doc); i += size {
+			write(t, r, doc[i:min(i+size, len(doc))])
+		}
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != want {
+			t.Errorf("chunks of %d:\n%s\nwant:\n%s", size, out, want)
+		}
+	}
+}
+
+func TestHighlighterBlockCommentAcrossLines(t *testing.T) {
+	setNoColor(t, false)
+	h := newHighlighter("go")
+	h.line("x := 1 /* start")
+	if !h.inBlockComment {
+		t.Fatal("block comment should stay open at the end of the line")
+	}
+	got := h.line("still a comment */ y := 2")
+	if h.inBlockComment {
+		t.Error("block comment should be closed")
+	}
+	if !strings.Contains(got, "\x1b[") || ansiRe.ReplaceAllString(got, "") != "still a comment */ y := 2" {
+		t.Errorf("highlighted line = %q", got)
+	}
+
+	if got := newHighlighter("nolang").line("x := 1"); got != "x := 1" {
+		t.Errorf("unknown language should be left alone, got %q", got)
+	}
+}
@@ -0,0 +1,41 @@
+package mockserver
+
+import "github.com/kdevrou/openrouter-cli/internal/api"
+
+// DefaultModels is served for /models when no fixture is given; it includes
+// the CLI's default model so a fresh config works against the mock
+var DefaultModels = []api.Model{
+	{
+		ID:            "openai/gpt-4",
+		Name:          "OpenAI: GPT-4",
+		Created:       1685232000,
+		ContextLength: 8191,
+		Pricing:       api.ModelPricing{Prompt: "0.00003", Completion: "0.00006"},
+		Architecture:  api.Architecture{Modality: "text->text", Tokenizer: "GPT"},
+	},
+	{
+		ID:            "openai/gpt-4o-mini",
+		Name:          "OpenAI: GPT-4o-mini",
+		Created:       1721260800,
+		ContextLength: 128000,
+		Pricing:       api.ModelPricing{Prompt: "0.00000015", Completion: "0.0000006"},
+		Architecture:  api.Architecture{Modality: "text+image->text", Tokenizer: "GPT"},
+	},
+	{
+		ID:            "anthropic/claude-3.5-sonnet",
+		Name:          "Anthropic: Claude 3.5 Sonnet",
+		Created:       1729555200,
+		ContextLength: 200000,
+		Pricing:       api.ModelPricing{Prompt: "0.000003", Completion: "0.000015"},
+		Architecture:  api.Architecture{Modality: "text+image->text", Tokenizer: "Claude"},
+	},
+	{
+		ID:            "mock/free",
+		Name:          "Mock: Free",
+		Created:       1735689600,
+		ContextLength: 32768,
+		Pricing:       api.ModelPricing{Prompt: "0", Completion: "0"},
+		Architecture:  api.Architecture{Modality: "text->text", Tokenizer: "Other"},
+		Description:   "A free model that only exists on the mock server",
+	},
+}
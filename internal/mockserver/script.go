@@ -0,0 +1,122 @@
+package mockserver
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Script is a list of rules for answering chat requests; the first rule
+// that matches a request answers it
+type Script struct {
+	Rules []Rule `yaml:"responses"`
+
+	mu sync.Mutex
+}
+
+// Rule answers the chat requests it matches with some content or an error
+// A rule with neither content nor an error status echoes the last user
+// message.
+type Rule struct {
+	Match        string `yaml:"match"`         // Regexp the last user message must match
+	Model        string `yaml:"model"`         // Glob the model must match, e.g. openai/*
+	Content      string `yaml:"content"`       // Reply text
+	FinishReason string `yaml:"finish_reason"` // Defaults to stop
+	Status       int    `yaml:"status"`        // HTTP status of an error reply, e.g. 429
+	Error        string `yaml:"error"`         // Error message; defaults to the status text
+	RetryAfter   int    `yaml:"retry_after"`   // Seconds for the Retry-After header
+	Delay        string `yaml:"delay"`         // Wait before replying, e.g. 500ms
+	Times        int    `yaml:"times"`         // Stop matching after this many replies; 0 for no limit
+
+	match *regexp.Regexp
+	delay time.Duration
+	used  int
+}
+
+// LoadScript reads and checks a script file
+func LoadScript(path string) (*Script, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read script: %w", err)
+	}
+	return ParseScript(data)
+}
+
+// ParseScript parses a script and reports every problem found
+func ParseScript(data []byte) (*Script, error) {
+	var script Script
+	decoder := yaml.NewDecoder(strings.NewReader(string(data)))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&script); err != nil {
+		return nil, fmt.Errorf("invalid script: %w", err)
+	}
+
+	var problems []string
+	for i := range script.Rules {
+		r := &script.Rules[i]
+		where := fmt.Sprintf("response %d", i+1)
+		if r.Match != "" {
+			re, err := regexp.Compile(r.Match)
+			if err != nil {
+				problems = append(problems, fmt.Sprintf("%s: invalid match: %v", where, err))
+			}
+			r.match = re
+		}
+		if _, err := path.Match(r.Model, ""); err != nil {
+			problems = append(problems, fmt.Sprintf("%s: invalid model pattern %q", where, r.Model))
+		}
+		if r.Delay != "" {
+			d, err := time.ParseDuration(r.Delay)
+			if err != nil || d < 0 {
+				problems = append(problems, fmt.Sprintf("%s: invalid delay %q", where, r.Delay))
+			}
+			r.delay = d
+		}
+		if r.Status != 0 && (r.Status < 400 || r.Status > 599) {
+			problems = append(problems, fmt.Sprintf("%s: status must be an error status (400-599)", where))
+		}
+		if r.Status != 0 && r.Content != "" {
+			problems = append(problems, fmt.Sprintf("%s: has both content and an error status", where))
+		}
+		if r.Times < 0 || r.RetryAfter < 0 {
+			problems = append(problems, fmt.Sprintf("%s: times and retry_after can't be negative", where))
+		}
+	}
+	if len(script.Rules) == 0 {
+		problems = append(problems, "no responses")
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid script:\n  %s", strings.Join(problems, "\n  "))
+	}
+	return &script, nil
+}
+
+// next returns the first rule matching model and input and counts it as
+// used, or nil when none matches
+func (s *Script) next(model, input string) *Rule {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.Rules {
+		r := &s.Rules[i]
+		if r.Times > 0 && r.used >= r.Times {
+			continue
+		}
+		if r.Model != "" {
+			if ok, _ := path.Match(r.Model, model); !ok {
+				continue
+			}
+		}
+		if r.match != nil && !r.match.MatchString(input) {
+			continue
+		}
+		r.used++
+		return r
+	}
+	return nil
+}
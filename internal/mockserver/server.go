@@ -0,0 +1,351 @@
+// Package mockserver is a stand-in for the OpenRouter API, for using and
+// testing the CLI offline. It answers chat completions by echoing the prompt,
+// with a fixed reply or from a script, and can stream and inject errors.
+package mockserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// Options configures a mock server
+type Options struct {
+	Response   string        // Reply to every unscripted request; empty echoes the prompt
+	Script     *Script       // Rules tried before Response; optional
+	Models     []byte        // Body served for /models; nil serves DefaultModels
+	APIKey     string        // Key requests must send; empty accepts any key
+	Credits    float64       // Credits reported by /credits
+	Latency    time.Duration // Wait before every chat reply
+	ChunkDelay time.Duration // Wait between streamed chunks
+	Log        io.Writer     // Gets one line per request; optional
+}
+
+// Server is an http.Handler serving the OpenRouter endpoints the CLI uses
+type Server struct {
+	opts    Options
+	models  []byte
+	pricing map[string]api.ModelPricing // Known models by ID
+
+	mu       sync.Mutex
+	requests []api.ChatCompletionRequest
+	usage    float64 // Dollars spent, by known model prices
+	nextID   int
+}
+
+// New returns a server, checking that the models fixture is a models list
+func New(opts Options) (*Server, error) {
+	s := &Server{opts: opts, models: opts.Models, pricing: make(map[string]api.ModelPricing)}
+	if s.models == nil {
+		data, err := json.Marshal(api.ModelsResponse{Data: DefaultModels})
+		if err != nil {
+			return nil, err
+		}
+		s.models = data
+	}
+	var models api.ModelsResponse
+	if err := json.Unmarshal(s.models, &models); err != nil {
+		return nil, fmt.Errorf("invalid models fixture: %w", err)
+	}
+	if len(models.Data) == 0 {
+		return nil, fmt.Errorf("invalid models fixture: no models in data")
+	}
+	for _, m := range models.Data {
+		s.pricing[m.ID] = m.Pricing
+	}
+	return s, nil
+}
+
+// LoadModels reads a models fixture: a saved response from the /models endpoint
+func LoadModels(path string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read models fixture: %w", err)
+	}
+	return data, nil
+}
+
+// Requests returns the chat requests received so far, in order
+func (s *Server) Requests() []api.ChatCompletionRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]api.ChatCompletionRequest(nil), s.requests...)
+}
+
+// ServeHTTP routes a request; paths may carry the /api/v1 or /v1 prefix of
+// the real API, so either form of base URL works
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route := r.URL.Path
+	for _, prefix := range []string{"/api/v1", "/v1"} {
+		if strings.HasPrefix(route, prefix+"/") {
+			route = strings.TrimPrefix(route, prefix)
+			break
+		}
+	}
+
+	if !s.authorized(r) {
+		s.log(r, http.StatusUnauthorized, "bad key")
+		writeError(w, http.StatusUnauthorized, "No auth credentials found")
+		return
+	}
+
+	switch {
+	case route == "/chat/completions" && r.Method == http.MethodPost:
+		s.chat(w, r)
+	case route == "/models" && r.Method == http.MethodGet:
+		s.log(r, http.StatusOK, "")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(s.models)
+	case route == "/key" && r.Method == http.MethodGet:
+		s.log(r, http.StatusOK, "")
+		writeJSON(w, http.StatusOK, api.KeyInfoResponse{Data: api.KeyInfo{Label: "mock", Usage: s.spent()}})
+	case route == "/credits" && r.Method == http.MethodGet:
+		s.log(r, http.StatusOK, "")
+		writeJSON(w, http.StatusOK, map[string]any{
+			"data": map[string]float64{"total_credits": s.opts.Credits, "total_usage": s.spent()},
+		})
+	default:
+		s.log(r, http.StatusNotFound, "")
+		writeError(w, http.StatusNotFound, fmt.Sprintf("%s %s is not a mock endpoint", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || key == "" {
+		return false
+	}
+	return s.opts.APIKey == "" || key == s.opts.APIKey
+}
+
+// chatRequest is a chat completion request with the stream option, which the
+// client doesn't send but other OpenAI-compatible clients do
+type chatRequest struct {
+	api.ChatCompletionRequest
+	Stream bool `json:"stream"`
+}
+
+func (s *Server) chat(w http.ResponseWriter, r *http.Request) {
+	var req chatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.log(r, http.StatusBadRequest, "bad json")
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+	s.mu.Lock()
+	s.requests = append(s.requests, req.ChatCompletionRequest)
+	s.nextID++
+	id := fmt.Sprintf("gen-mock-%d", s.nextID)
+	s.mu.Unlock()
+
+	pricing, known := s.pricing[req.Model]
+	if !known && req.Model != "openrouter/auto" {
+		s.log(r, http.StatusBadRequest, req.Model)
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("%s is not a valid model ID", req.Model))
+		return
+	}
+	if len(req.Messages) == 0 {
+		s.log(r, http.StatusBadRequest, req.Model)
+		writeError(w, http.StatusBadRequest, "messages must not be empty")
+		return
+	}
+
+	input := lastUserMessage(req.Messages)
+	content, finish, source := input, "stop", "echo"
+	if s.opts.Response != "" {
+		content, source = s.opts.Response, "canned"
+	}
+	delay := s.opts.Latency
+	if s.opts.Script != nil {
+		if rule := s.opts.Script.next(req.Model, input); rule != nil {
+			source = "scripted"
+			delay += rule.delay
+			if rule.Status != 0 {
+				time.Sleep(delay)
+				if rule.RetryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(rule.RetryAfter))
+				}
+				message := rule.Error
+				if message == "" {
+					message = http.StatusText(rule.Status)
+				}
+				s.log(r, rule.Status, req.Model+" scripted")
+				writeError(w, rule.Status, message)
+				return
+			}
+			if rule.Content != "" {
+				content = rule.Content
+			} else {
+				content = input
+			}
+			if rule.FinishReason != "" {
+				finish = rule.FinishReason
+			}
+		}
+	}
+	time.Sleep(delay)
+
+	usage := api.Usage{PromptTokens: promptTokens(req.Messages), CompletionTokens: tokens(content)}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	if cost, ok := pricing.Cost(usage); ok {
+		s.mu.Lock()
+		s.usage += cost
+		s.mu.Unlock()
+	}
+
+	s.log(r, http.StatusOK, req.Model+" "+source)
+	resp := api.ChatCompletionResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []api.Choice{{Message: api.Message{Role: "assistant", Content: content}, FinishReason: finish}},
+		Usage:   usage,
+	}
+	if req.Stream {
+		s.stream(w, resp)
+		return
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// streamChunk is one server-sent event of a streamed chat completion
+type streamChunk struct {
+	ID      string        `json:"id"`
+	Object  string        `json:"object"`
+	Created int64         `json:"created"`
+	Model   string        `json:"model"`
+	Choices []streamDelta `json:"choices"`
+	Usage   *api.Usage    `json:"usage,omitempty"`
+}
+
+type streamDelta struct {
+	Index        int     `json:"index"`
+	Delta        delta   `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// delta is the part of the message in one chunk; only the first has the role
+type delta struct {
+	Role    string `json:"role,omitempty"`
+	Content string `json:"content,omitempty"`
+}
+
+// stream sends resp as server-sent events, a word at a time, ending with
+// the finish reason and usage and then [DONE]
+func (s *Server) stream(w http.ResponseWriter, resp api.ChatCompletionResponse) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	send := func(chunk streamChunk) {
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	chunk := func(d delta, finish *string) streamChunk {
+		return streamChunk{
+			ID: resp.ID, Object: "chat.completion.chunk", Created: resp.Created, Model: resp.Model,
+			Choices: []streamDelta{{Delta: d, FinishReason: finish}},
+		}
+	}
+
+	choice := resp.Choices[0]
+	for i, word := range splitWords(choice.Message.Content) {
+		d := delta{Content: word}
+		if i == 0 {
+			d.Role = "assistant"
+		} else {
+			time.Sleep(s.opts.ChunkDelay)
+		}
+		send(chunk(d, nil))
+	}
+	last := chunk(delta{}, &choice.FinishReason)
+	last.Usage = &resp.Usage
+	send(last)
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	if flusher != nil {
+		flusher.Flush()
+	}
+}
+
+// splitWords splits text into words, each keeping the spaces before it, so
+// the pieces join back into text
+func splitWords(text string) []string {
+	var words []string
+	start := 0
+	for i := 1; i < len(text); i++ {
+		if text[i] == ' ' && text[i-1] != ' ' {
+			words = append(words, text[start:i])
+			start = i
+		}
+	}
+	if start < len(text) {
+		words = append(words, text[start:])
+	}
+	return words
+}
+
+func (s *Server) spent() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usage
+}
+
+func (s *Server) log(r *http.Request, status int, detail string) {
+	if s.opts.Log == nil {
+		return
+	}
+	line := fmt.Sprintf("%s %s %d", r.Method, r.URL.Path, status)
+	if detail != "" {
+		line += " " + detail
+	}
+	fmt.Fprintln(s.opts.Log, line)
+}
+
+func lastUserMessage(messages []api.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" {
+			return messages[i].Content
+		}
+	}
+	return messages[len(messages)-1].Content
+}
+
+// tokens estimates the tokens in text at about four characters each
+func tokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (len(text) + 3) / 4
+}
+
+func promptTokens(messages []api.Message) int {
+	total := 0
+	for _, m := range messages {
+		total += tokens(m.Content)
+	}
+	return total
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the API's {"error": {"code", "message"}} form
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]any{"code": status, "message": message}})
+}
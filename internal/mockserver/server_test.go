@@ -0,0 +1,258 @@
+package mockserver
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+const testScript = `
+responses:
+  - match: "(?i)weather"
+    content: "Sunny and 22°C."
+  - match: flaky
+    status: 429
+    retry_after: 2
+    times: 1
+  - match: outage
+    status: 502
+    error: Upstream provider unavailable
+`
+
+// newTestClient starts a mock server running testScript and returns it with
+// a client pointed at it
+func newTestClient(t *testing.T, opts Options) (*Server, *api.Client) {
+	t.Helper()
+	script, err := ParseScript([]byte(testScript))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Script = script
+	mock, err := New(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(mock)
+	t.Cleanup(server.Close)
+	client := api.NewClient(server.URL+"/api/v1", "test-key", 10)
+	client.Limiter = nil
+	return mock, client
+}
+
+func newChatRequest(model, content string) *api.ChatCompletionRequest {
+	temp := 0.0
+	return &api.ChatCompletionRequest{
+		Model:       model,
+		Messages:    []api.Message{{Role: "system", Content: "Be brief."}, {Role: "user", Content: content}},
+		Temperature: &temp,
+	}
+}
+
+func TestChat(t *testing.T) {
+	mock, client := newTestClient(t, Options{})
+
+	resp, err := client.SendChatCompletion(newChatRequest("openai/gpt-4", "hello there"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "hello there" {
+		t.Errorf("echo = %q, want the last user message", got)
+	}
+	if resp.Usage.TotalTokens == 0 || resp.Usage.TotalTokens != resp.Usage.PromptTokens+resp.Usage.CompletionTokens {
+		t.Errorf("usage = %+v", resp.Usage)
+	}
+
+	resp, err = client.SendChatCompletion(newChatRequest("mock/free", "What's the weather?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "Sunny and 22°C." {
+		t.Errorf("scripted reply = %q", got)
+	}
+
+	requests := mock.Requests()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if requests[1].Model != "mock/free" || len(requests[1].Messages) != 2 {
+		t.Errorf("second request = %+v", requests[1])
+	}
+	if requests[0].Temperature == nil || *requests[0].Temperature != 0 {
+		t.Errorf("temperature = %v, want an explicit 0", requests[0].Temperature)
+	}
+}
+
+func TestCannedResponse(t *testing.T) {
+	_, client := newTestClient(t, Options{Response: "OK"})
+	resp, err := client.SendChatCompletion(newChatRequest("openai/gpt-4", "anything"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Choices[0].Message.Content; got != "OK" {
+		t.Errorf("reply = %q, want OK", got)
+	}
+}
+
+func TestScriptedErrors(t *testing.T) {
+	mock, client := newTestClient(t, Options{})
+
+	_, err := client.SendChatCompletion(newChatRequest("openai/gpt-4", "a flaky request"))
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("first flaky request: err = %v, want a 429", err)
+	}
+	if apiErr.RetryAfter != 2*time.Second {
+		t.Errorf("RetryAfter = %v, want 2s", apiErr.RetryAfter)
+	}
+	if !apiErr.IsRetryable() {
+		t.Error("429 should be retryable")
+	}
+
+	// times: 1, so the retry gets through
+	if _, err := client.SendChatCompletion(newChatRequest("openai/gpt-4", "a flaky request")); err != nil {
+		t.Fatalf("retried flaky request: %v", err)
+	}
+
+	_, err = client.SendChatCompletion(newChatRequest("openai/gpt-4", "outage"))
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadGateway || apiErr.Message != "Upstream provider unavailable" {
+		t.Fatalf("outage: err = %v, want the scripted 502", err)
+	}
+	if !apiErr.IsProviderUnavailable() {
+		t.Error("502 should count as provider unavailable")
+	}
+
+	_, err = client.SendChatCompletion(newChatRequest("nope/x", "hi"))
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Fatalf("unknown model: err = %v, want a 400", err)
+	}
+
+	if n := len(mock.Requests()); n != 4 {
+		t.Errorf("got %d requests, want 4", n)
+	}
+}
+
+func TestStream(t *testing.T) {
+	mock, client := newTestClient(t, Options{})
+	body := `{"model":"mock/free","stream":true,"messages":[{"role":"user","content":"one two  three"}]}`
+	resp, err := client.ForwardChatCompletion(context.Background(), []byte(body), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+
+	var content strings.Builder
+	var usage *api.Usage
+	var finish string
+	done := false
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		if data == "[DONE]" {
+			done = true
+			break
+		}
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			t.Fatalf("bad chunk %q: %v", data, err)
+		}
+		content.WriteString(chunk.Choices[0].Delta.Content)
+		if chunk.Choices[0].FinishReason != nil {
+			finish = *chunk.Choices[0].FinishReason
+		}
+		if chunk.Usage != nil {
+			usage = chunk.Usage
+		}
+	}
+	if !done {
+		t.Error("stream didn't end with [DONE]")
+	}
+	if content.String() != "one two  three" {
+		t.Errorf("streamed content = %q", content.String())
+	}
+	if finish != "stop" || usage == nil || usage.TotalTokens == 0 {
+		t.Errorf("finish = %q, usage = %+v", finish, usage)
+	}
+	if requests := mock.Requests(); len(requests) != 1 || requests[0].Model != "mock/free" {
+		t.Errorf("requests = %+v", requests)
+	}
+}
+
+func TestModels(t *testing.T) {
+	_, client := newTestClient(t, Options{})
+	models, err := client.ListModels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != len(DefaultModels) || models[0].ID != "openai/gpt-4" {
+		t.Fatalf("models = %+v", models)
+	}
+
+	fixture := []byte(`{"data":[{"id":"acme/tiny","name":"Tiny","pricing":{"prompt":"0","completion":"0"}}]}`)
+	_, client = newTestClient(t, Options{Models: fixture})
+	models, err = client.ListModels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].ID != "acme/tiny" {
+		t.Fatalf("fixture models = %+v", models)
+	}
+	if _, err := client.SendChatCompletion(newChatRequest("openai/gpt-4", "hi")); err == nil {
+		t.Error("a model missing from the fixture should be rejected")
+	}
+}
+
+func TestKeyAndCredits(t *testing.T) {
+	_, client := newTestClient(t, Options{Credits: 5})
+	if _, err := client.SendChatCompletion(newChatRequest("openai/gpt-4", "spend something")); err != nil {
+		t.Fatal(err)
+	}
+	info, err := client.GetKeyInfo()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Label != "mock" || info.Usage <= 0 {
+		t.Errorf("key info = %+v, want usage from the chat request", info)
+	}
+}
+
+func TestRequireKey(t *testing.T) {
+	_, client := newTestClient(t, Options{APIKey: "right"})
+	_, err := client.ListModels()
+	var apiErr *api.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("wrong key: err = %v, want a 401", err)
+	}
+}
+
+func TestParseScriptProblems(t *testing.T) {
+	_, err := ParseScript([]byte(`
+responses:
+  - match: "("
+  - status: 200
+  - status: 429
+    content: both
+  - delay: soon
+`))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"response 1: invalid match", "response 2: status", "response 3: has both", "response 4: invalid delay"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't mention %q", err, want)
+		}
+	}
+}
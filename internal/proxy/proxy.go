@@ -0,0 +1,412 @@
+// Package proxy serves an OpenAI-compatible API on the local machine and
+// forwards its requests to OpenRouter, so tools that speak the OpenAI API
+// can use the configured key without ever seeing it
+package proxy
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+)
+
+// maxBodySize is the largest request body accepted
+const maxBodySize = 32 << 20
+
+// forwardedHeaders are copied from OpenRouter's responses to the caller
+var forwardedHeaders = []string{"Content-Type", "Cache-Control", "Retry-After"}
+
+// Defaults fill in what a request leaves out
+type Defaults struct {
+	Model       string
+	Temperature float64
+	MaxTokens   int // 0 leaves max_tokens unset
+	Provider    *api.ProviderPreferences
+}
+
+// Budget caps what the proxy spends, in dollars; a zero cap is no cap
+// Only requests whose cost is known count toward it
+type Budget struct {
+	PerRun float64 // Since the server started
+	PerDay float64 // Since local midnight
+}
+
+// Entry records one chat request the proxy handled
+type Entry struct {
+	Time       time.Time  `json:"time"`
+	Model      string     `json:"model"`
+	Stream     bool       `json:"stream,omitempty"`
+	Status     int        `json:"status"`
+	Usage      *api.Usage `json:"usage,omitempty"`
+	Cost       *float64   `json:"cost,omitempty"` // Dollars, when the model's price is known
+	Cached     bool       `json:"cached,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	DurationMS int64      `json:"duration_ms"`
+}
+
+// Server is an http.Handler for /v1/chat/completions and /v1/models
+type Server struct {
+	Client   *api.Client
+	Token    string // Bearer token callers must send
+	Defaults Defaults
+	Resolve  func(name string) (string, error)                   // Resolves model aliases; optional
+	Aliases  []string                                            // Listed by /v1/models beside the catalog
+	Cost     func(model string, usage api.Usage) (float64, bool) // Optional
+	Budget   Budget                                              // Needs Cost
+	Log      func(Entry)                                         // Called after every chat request; optional
+	Now      func() time.Time                                    // Defaults to time.Now
+
+	mu     sync.Mutex
+	models []api.Model // Catalog, fetched on first use
+
+	spendMu  sync.Mutex
+	spentRun float64
+	spentDay float64
+	day      string // Local date spentDay is for
+}
+
+// ServeHTTP checks the caller's token and routes the request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "authentication_error", "invalid or missing local token")
+		return
+	}
+	switch {
+	case r.URL.Path == "/v1/chat/completions" && r.Method == http.MethodPost:
+		s.chat(w, r)
+	case r.URL.Path == "/v1/models" && r.Method == http.MethodGet:
+		s.listModels(w)
+	default:
+		writeError(w, http.StatusNotFound, "invalid_request_error", fmt.Sprintf("%s %s is not supported", r.Method, r.URL.Path))
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && s.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(s.Token)) == 1
+}
+
+func (s *Server) now() time.Time {
+	if s.Now != nil {
+		return s.Now()
+	}
+	return time.Now()
+}
+
+// openAIModel is a model in the OpenAI list format
+type openAIModel struct {
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// catalog returns OpenRouter's models, fetching them the first time
+func (s *Server) catalog() ([]api.Model, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.models == nil {
+		models, err := s.Client.ListModels()
+		if err != nil {
+			return nil, err
+		}
+		s.models = models
+	}
+	return s.models, nil
+}
+
+// listModels lists OpenRouter's models and the configured aliases
+func (s *Server) listModels(w http.ResponseWriter) {
+	models, err := s.catalog()
+	if err != nil {
+		s.writeUpstreamError(w, err)
+		return
+	}
+	data := make([]openAIModel, 0, len(s.Aliases)+len(models))
+	for _, alias := range s.Aliases {
+		data = append(data, openAIModel{ID: alias, Object: "model", OwnedBy: "alias"})
+	}
+	for _, m := range models {
+		owner, _, _ := strings.Cut(m.ID, "/")
+		data = append(data, openAIModel{ID: m.ID, Object: "model", Created: m.Created, OwnedBy: owner})
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"object": "list", "data": data})
+}
+
+// chat fills in defaults and resolves the model, then sends the request
+// as prepared. Requests that fit api.ChatCompletionRequest go through
+// SendChatCompletionBody, and so the response cache; the rest, including
+// streams, are forwarded with ForwardChatCompletion.
+func (s *Server) chat(w http.ResponseWriter, r *http.Request) {
+	entry := Entry{Time: s.now()}
+	defer func() {
+		entry.DurationMS = s.now().Sub(entry.Time).Milliseconds()
+		if entry.Cost != nil {
+			s.AddSpend(entry.Time, *entry.Cost, true)
+		}
+		if s.Log != nil {
+			s.Log(entry)
+		}
+	}()
+	fail := func(status int, kind, message string) {
+		entry.Status, entry.Error = status, message
+		writeError(w, status, kind, message)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(r.Body, maxBodySize+1))
+	if err != nil {
+		fail(http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("failed to read request: %v", err))
+		return
+	}
+	if len(data) > maxBodySize {
+		fail(http.StatusRequestEntityTooLarge, "invalid_request_error", "request body is too large")
+		return
+	}
+	body, model, stream, err := s.prepare(data)
+	if err != nil {
+		fail(http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	entry.Model, entry.Stream = model, stream
+	if message := s.overBudget(entry.Time); message != "" {
+		fail(http.StatusPaymentRequired, "insufficient_quota", message)
+		return
+	}
+
+	var req api.ChatCompletionRequest
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.DisallowUnknownFields()
+	if !stream && decoder.Decode(&req) == nil {
+		resp, err := s.Client.SendChatCompletionBody(&req, body)
+		if err != nil {
+			entry.Status, entry.Error = s.writeUpstreamError(w, err), err.Error()
+			return
+		}
+		entry.Status, entry.Cached = http.StatusOK, resp.Cached
+		s.account(&entry, resp.Model, &resp.Usage)
+		if resp.Cached {
+			w.Header().Set("X-Cache", "HIT")
+		}
+		writeJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	resp, err := s.Client.ForwardChatCompletion(r.Context(), body, stream)
+	if err != nil {
+		entry.Status, entry.Error = s.writeUpstreamError(w, err), err.Error()
+		return
+	}
+	defer resp.Body.Close()
+	for _, name := range forwardedHeaders {
+		if value := resp.Header.Get(name); value != "" {
+			w.Header().Set(name, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	entry.Status = resp.StatusCode
+
+	var result *usageResult
+	if stream && resp.StatusCode == http.StatusOK {
+		result, err = copyStream(w, resp.Body)
+	} else {
+		result, err = copyBody(w, resp.Body)
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	} else if resp.StatusCode >= 400 {
+		entry.Error = http.StatusText(resp.StatusCode)
+	}
+	if result != nil && result.Usage != nil {
+		s.account(&entry, result.Model, result.Usage)
+	}
+}
+
+// prepare resolves the request's model and adds the defaults it leaves out,
+// keeping every other field as it is
+func (s *Server) prepare(data []byte) (body []byte, model string, stream bool, err error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, "", false, fmt.Errorf("invalid JSON body: %v", err)
+	}
+	if raw, ok := fields["model"]; ok {
+		if err := json.Unmarshal(raw, &model); err != nil {
+			return nil, "", false, fmt.Errorf("model must be a string")
+		}
+	}
+	if model == "" {
+		model = s.Defaults.Model
+	}
+	if s.Resolve != nil {
+		if model, err = s.Resolve(model); err != nil {
+			return nil, "", false, err
+		}
+	}
+	if raw, ok := fields["stream"]; ok {
+		if err := json.Unmarshal(raw, &stream); err != nil {
+			return nil, "", false, fmt.Errorf("stream must be a boolean")
+		}
+		if !stream {
+			// Leaving it out lets the request use the cache
+			delete(fields, "stream")
+		}
+	}
+
+	set := func(name string, value any) {
+		if _, ok := fields[name]; !ok {
+			fields[name], _ = json.Marshal(value)
+		}
+	}
+	fields["model"], _ = json.Marshal(model)
+	set("temperature", s.Defaults.Temperature)
+	_, hasMaxCompletion := fields["max_completion_tokens"]
+	if s.Defaults.MaxTokens > 0 && !hasMaxCompletion {
+		set("max_tokens", s.Defaults.MaxTokens)
+	}
+	if s.Defaults.Provider != nil {
+		set("provider", s.Defaults.Provider)
+	}
+	body, err = json.Marshal(fields)
+	return body, model, stream, err
+}
+
+// account adds the usage and its cost to an entry
+func (s *Server) account(entry *Entry, model string, usage *api.Usage) {
+	entry.Usage = usage
+	if model == "" {
+		model = entry.Model
+	}
+	if s.Cost == nil || entry.Cached {
+		return
+	}
+	if cost, ok := s.Cost(model, *usage); ok {
+		entry.Cost = &cost
+	}
+}
+
+// AddSpend counts cost, in dollars, toward the budget as spent at t
+// Spending from earlier runs counts toward the daily cap only, so serve
+// passes the costs it logged earlier today with thisRun false.
+func (s *Server) AddSpend(t time.Time, cost float64, thisRun bool) {
+	s.spendMu.Lock()
+	defer s.spendMu.Unlock()
+	day := t.Local().Format(time.DateOnly)
+	if thisRun {
+		s.spentRun += cost
+	}
+	switch {
+	case day == s.day:
+		s.spentDay += cost
+	case day > s.day:
+		s.day, s.spentDay = day, cost
+	}
+}
+
+// overBudget describes the cap that has been reached, or returns ""
+func (s *Server) overBudget(now time.Time) string {
+	s.spendMu.Lock()
+	defer s.spendMu.Unlock()
+	if s.Budget.PerRun > 0 && s.spentRun >= s.Budget.PerRun {
+		return fmt.Sprintf("spending cap reached: $%.4f spent since the proxy started (cap $%g)", s.spentRun, s.Budget.PerRun)
+	}
+	if s.Budget.PerDay > 0 && s.day == now.Local().Format(time.DateOnly) && s.spentDay >= s.Budget.PerDay {
+		return fmt.Sprintf("daily spending cap reached: $%.4f spent today (cap $%g)", s.spentDay, s.Budget.PerDay)
+	}
+	return ""
+}
+
+// writeUpstreamError reports a failed OpenRouter request and returns the
+// status it used
+func (s *Server) writeUpstreamError(w http.ResponseWriter, err error) int {
+	var apiErr *api.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		kind := apiErr.Type
+		if kind == "" {
+			kind = "api_error"
+		}
+		writeError(w, apiErr.StatusCode, kind, apiErr.Message)
+		return apiErr.StatusCode
+	case errors.Is(err, api.ErrCacheMiss):
+		writeError(w, http.StatusServiceUnavailable, "cache_miss", err.Error())
+		return http.StatusServiceUnavailable
+	default:
+		writeError(w, http.StatusBadGateway, "api_error", err.Error())
+		return http.StatusBadGateway
+	}
+}
+
+// usageResult is the part of a response the proxy reads for its log
+type usageResult struct {
+	Model string     `json:"model"`
+	Usage *api.Usage `json:"usage"`
+}
+
+// copyBody copies a whole response and reads its usage
+func copyBody(w io.Writer, body io.Reader) (*usageResult, error) {
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	var result usageResult
+	if json.Unmarshal(data, &result) != nil {
+		return nil, nil
+	}
+	return &result, nil
+}
+
+// copyStream passes server-sent events through as they arrive, flushing
+// after each one, and reads the usage from the chunk that carries it
+func copyStream(w io.Writer, body io.Reader) (*usageResult, error) {
+	flusher, _ := w.(http.Flusher)
+	reader := bufio.NewReader(body)
+	var result *usageResult
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) > 0 {
+			if _, werr := w.Write(line); werr != nil {
+				return result, werr
+			}
+			if payload, ok := bytes.CutPrefix(bytes.TrimSpace(line), []byte("data:")); ok && bytes.Contains(payload, []byte(`"usage"`)) {
+				var chunk usageResult
+				if json.Unmarshal(bytes.TrimSpace(payload), &chunk) == nil && chunk.Usage != nil {
+					result = &chunk
+				}
+			}
+			if flusher != nil && len(bytes.TrimSpace(line)) == 0 {
+				flusher.Flush()
+			}
+		}
+		if err == io.EOF {
+			if flusher != nil {
+				flusher.Flush()
+			}
+			return result, nil
+		}
+		if err != nil {
+			return result, fmt.Errorf("stream interrupted: %w", err)
+		}
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an error in the OpenAI {"error": {"message", "type"}} form
+func writeError(w http.ResponseWriter, status int, kind, message string) {
+	writeJSON(w, status, map[string]any{"error": map[string]any{"message": message, "type": kind, "code": status}})
+}
@@ -0,0 +1,125 @@
+package proxy
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kdevrou/openrouter-cli/internal/api"
+	"github.com/kdevrou/openrouter-cli/internal/mockserver"
+)
+
+// newProxy returns a proxy in front of a mock API where every request
+// costs 60 cents
+func newProxy(t *testing.T, budget Budget) (*Server, *mockserver.Server) {
+	t.Helper()
+	mock, err := mockserver.New(mockserver.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upstream := httptest.NewServer(mock)
+	t.Cleanup(upstream.Close)
+
+	client := api.NewClient(upstream.URL, "key", 10)
+	client.Limiter = nil
+	return &Server{
+		Client:   client,
+		Token:    "local",
+		Defaults: Defaults{Model: "openai/gpt-4", Temperature: 1},
+		Cost:     func(string, api.Usage) (float64, bool) { return 0.6, true },
+		Budget:   budget,
+	}, mock
+}
+
+// post sends a chat request body to the proxy and returns the response
+func post(t *testing.T, s *Server, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, "/v1/chat/completions", strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer local")
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, req)
+	return w
+}
+
+func TestChatKeepsZeroTemperature(t *testing.T) {
+	s, mock := newProxy(t, Budget{})
+	for _, body := range []string{
+		`{"messages":[{"role":"user","content":"hi"}],"temperature":0}`,
+		`{"messages":[{"role":"user","content":"hi"}],"temperature":0,"top_p":0.5}`,
+		`{"messages":[{"role":"user","content":"hi"}]}`,
+	} {
+		if w := post(t, s, body); w.Code != http.StatusOK {
+			t.Fatalf("%s: status %d: %s", body, w.Code, w.Body)
+		}
+	}
+
+	requests := mock.Requests()
+	if len(requests) != 3 {
+		t.Fatalf("got %d requests, want 3", len(requests))
+	}
+	for i, want := range []float64{0, 0, 1} {
+		if got := requests[i].Temperature; got == nil || *got != want {
+			t.Errorf("request %d temperature = %v, want %v", i, got, want)
+		}
+	}
+	if requests[0].Model != "openai/gpt-4" {
+		t.Errorf("model = %q, want the default", requests[0].Model)
+	}
+}
+
+func TestBudgetPerRun(t *testing.T) {
+	s, mock := newProxy(t, Budget{PerRun: 1})
+	body := `{"messages":[{"role":"user","content":"hi"}]}`
+	for range 2 {
+		if w := post(t, s, body); w.Code != http.StatusOK {
+			t.Fatalf("status %d under the cap: %s", w.Code, w.Body)
+		}
+	}
+
+	w := post(t, s, body)
+	if w.Code != http.StatusPaymentRequired {
+		t.Fatalf("status %d over the cap, want 402", w.Code)
+	}
+	var resp struct {
+		Error struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error.Type != "insufficient_quota" || !strings.Contains(resp.Error.Message, "$1.2000") {
+		t.Errorf("error = %+v", resp.Error)
+	}
+	if n := len(mock.Requests()); n != 2 {
+		t.Errorf("%d requests reached the API, want 2", n)
+	}
+}
+
+func TestBudgetPerDay(t *testing.T) {
+	now := time.Date(2025, 6, 2, 15, 0, 0, 0, time.Local)
+	s, _ := newProxy(t, Budget{PerDay: 5})
+	s.Now = func() time.Time { return now }
+
+	// Yesterday's spending doesn't count; earlier today's does
+	s.AddSpend(now.AddDate(0, 0, -1), 100, false)
+	s.AddSpend(now.Add(-time.Hour), 4.5, false)
+
+	body := `{"messages":[{"role":"user","content":"hi"}]}`
+	if w := post(t, s, body); w.Code != http.StatusOK {
+		t.Fatalf("status %d under the cap: %s", w.Code, w.Body)
+	}
+	if w := post(t, s, body); w.Code != http.StatusPaymentRequired {
+		t.Fatalf("status %d over the cap, want 402", w.Code)
+	}
+
+	// The cap resets at midnight
+	now = now.Add(12 * time.Hour)
+	if w := post(t, s, body); w.Code != http.StatusOK {
+		t.Fatalf("status %d the next day: %s", w.Code, w.Body)
+	}
+}
@@ -0,0 +1,308 @@
+// Package templates loads prompt templates: files with YAML front-matter
+// followed by a Go text/template body
+package templates
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Ext is the file extension of template files
+const Ext = ".md"
+
+// InputVar is the variable holding the prompt argument and piped input
+const InputVar = "input"
+
+// ErrNotFound is returned by Find when no directory has the template
+var ErrNotFound = errors.New("template not found")
+
+// frontMatterDelim opens and closes a template's front-matter
+const frontMatterDelim = "---"
+
+// Meta is a template's front-matter
+// Model, Temperature and MaxTokens override the configured defaults but not
+// command-line flags.
+type Meta struct {
+	Description string            `yaml:"description,omitempty"`
+	Model       string            `yaml:"model,omitempty"`
+	Temperature *float64          `yaml:"temperature,omitempty"`
+	MaxTokens   *int              `yaml:"max_tokens,omitempty"`
+	System      string            `yaml:"system,omitempty"`
+	Vars        []string          `yaml:"vars,omitempty"`     // Required variables
+	Defaults    map[string]string `yaml:"defaults,omitempty"` // Optional variables
+}
+
+// Template is a parsed prompt template
+type Template struct {
+	Name string
+	Path string
+	Meta
+
+	body   *template.Template
+	system *template.Template
+}
+
+// Parse parses a template file's content
+func Parse(name, path string, data []byte) (*Template, error) {
+	meta, body, err := splitFrontMatter(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	t := &Template{Name: name, Path: path, Meta: meta}
+	if t.body, err = compile(name, body); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if t.system, err = compile(name+" (system)", meta.System); err != nil {
+		return nil, fmt.Errorf("%s: system: %w", path, err)
+	}
+	return t, nil
+}
+
+// splitFrontMatter separates the YAML front-matter from the body
+// Files without front-matter are all body.
+func splitFrontMatter(data []byte) (Meta, string, error) {
+	var meta Meta
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	if !strings.HasPrefix(text, frontMatterDelim+"\n") {
+		return meta, text, nil
+	}
+
+	rest := text[len(frontMatterDelim)+1:]
+	end := strings.Index(rest, "\n"+frontMatterDelim+"\n")
+	if end < 0 {
+		if !strings.HasSuffix(rest, "\n"+frontMatterDelim) {
+			return meta, "", fmt.Errorf("front-matter is not closed with %q", frontMatterDelim)
+		}
+		end = len(rest) - len(frontMatterDelim) - 1
+	}
+
+	decoder := yaml.NewDecoder(strings.NewReader(rest[:end]))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&meta); err != nil && !errors.Is(err, io.EOF) {
+		return meta, "", fmt.Errorf("invalid front-matter: %w", err)
+	}
+	body := ""
+	if start := end + len(frontMatterDelim) + 2; start < len(rest) {
+		body = rest[start:]
+	}
+	return meta, strings.TrimLeft(body, "\n"), nil
+}
+
+func compile(name, text string) (*template.Template, error) {
+	return template.New(name).Option("missingkey=error").Parse(text)
+}
+
+// Variables returns the variables the template uses, sorted: those declared
+// in vars and defaults, and those referenced as {{.name}} in the body or
+// system prompt
+func (t *Template) Variables() []string {
+	set := make(map[string]bool)
+	for _, v := range t.Vars {
+		set[v] = true
+	}
+	for v := range t.Defaults {
+		set[v] = true
+	}
+	for _, tmpl := range []*template.Template{t.body, t.system} {
+		if tmpl.Tree != nil {
+			collectFields(tmpl.Tree.Root, set)
+		}
+	}
+	names := make([]string, 0, len(set))
+	for v := range set {
+		names = append(names, v)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Required returns the variables that must be supplied: those declared in
+// vars or referenced without a default. The input variable is included when
+// the template uses it.
+func (t *Template) Required() []string {
+	var required []string
+	for _, v := range t.Variables() {
+		if _, ok := t.Defaults[v]; !ok {
+			required = append(required, v)
+		}
+	}
+	return required
+}
+
+// collectFields adds the top-level fields referenced with the template's own
+// dot; the bodies of range and with blocks change dot and are skipped
+func collectFields(node parse.Node, set map[string]bool) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, set)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, set)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				collectFields(arg, set)
+			}
+		}
+	case *parse.FieldNode:
+		set[n.Ident[0]] = true
+	case *parse.IfNode:
+		collectFields(n.Pipe, set)
+		collectFields(n.List, set)
+		collectFields(n.ElseList, set)
+	case *parse.RangeNode:
+		collectFields(n.Pipe, set)
+		collectFields(n.ElseList, set)
+	case *parse.WithNode:
+		collectFields(n.Pipe, set)
+		collectFields(n.ElseList, set)
+	case *parse.TemplateNode:
+		collectFields(n.Pipe, set)
+	}
+}
+
+// Render executes the body and system prompt with vars, which are merged
+// over the template's defaults. Missing variables are reported together
+// before anything is rendered.
+func (t *Template) Render(vars map[string]string) (prompt, system string, err error) {
+	data := make(map[string]string, len(t.Defaults)+len(vars))
+	for k, v := range t.Defaults {
+		data[k] = v
+	}
+	for k, v := range vars {
+		data[k] = v
+	}
+
+	var missing []string
+	for _, v := range t.Required() {
+		if _, ok := data[v]; !ok {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		return "", "", &MissingVarsError{Template: t.Name, Vars: missing}
+	}
+
+	var buf bytes.Buffer
+	if err := t.body.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("template %s: %w", t.Name, err)
+	}
+	prompt = buf.String()
+	buf.Reset()
+	if err := t.system.Execute(&buf, data); err != nil {
+		return "", "", fmt.Errorf("template %s: %w", t.Name, err)
+	}
+	return strings.TrimSpace(prompt), strings.TrimSpace(buf.String()), nil
+}
+
+// MissingVarsError lists the variables a template needs but was not given
+type MissingVarsError struct {
+	Template string
+	Vars     []string
+}
+
+func (e *MissingVarsError) Error() string {
+	var flags []string
+	for _, v := range e.Vars {
+		if v == InputVar {
+			continue
+		}
+		flags = append(flags, "--var "+v+"=...")
+	}
+	msg := fmt.Sprintf("template %s is missing variable(s): %s", e.Template, strings.Join(e.Vars, ", "))
+	if len(flags) < len(e.Vars) {
+		msg += " (" + InputVar + " comes from the prompt argument or stdin)"
+	}
+	if len(flags) > 0 {
+		msg += "; set them with " + strings.Join(flags, " ")
+	}
+	return msg
+}
+
+// Find loads the named template from the first directory that has it
+func Find(dirs []string, name string) (*Template, error) {
+	if strings.ContainsAny(name, `/\`) {
+		return nil, fmt.Errorf("invalid template name %q", name)
+	}
+	for _, dir := range dirs {
+		path := filepath.Join(dir, name+Ext)
+		data, err := os.ReadFile(path)
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template: %w", err)
+		}
+		return Parse(name, path, data)
+	}
+	return nil, fmt.Errorf("%w: %s (see 'openrouter templates list')", ErrNotFound, name)
+}
+
+// Entry is a template found by List, which may have failed to parse
+type Entry struct {
+	Name     string
+	Path     string
+	Template *Template // nil when Err is set
+	Err      error
+	Shadowed bool // A directory earlier in the search order has the same name
+}
+
+// List returns every template in dirs, sorted by name, with templates
+// shadowed by an earlier directory marked
+func List(dirs []string) []Entry {
+	var entries []Entry
+	seen := make(map[string]bool)
+	for _, dir := range dirs {
+		matches, _ := filepath.Glob(filepath.Join(dir, "*"+Ext))
+		for _, path := range matches {
+			name := strings.TrimSuffix(filepath.Base(path), Ext)
+			entry := Entry{Name: name, Path: path, Shadowed: seen[name]}
+			seen[name] = true
+			if data, err := os.ReadFile(path); err != nil {
+				entry.Err = err
+			} else {
+				entry.Template, entry.Err = Parse(name, path, data)
+			}
+			entries = append(entries, entry)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Skeleton returns the content of a new template file
+func Skeleton(name string) string {
+	return `---
+description: Describe what the ` + name + ` template is for
+# model: anthropic/claude-3.5-sonnet
+# temperature: 0.2
+# max_tokens: 2000
+system: You are a helpful assistant.
+vars:
+  - topic
+defaults:
+  tone: concise
+---
+Write a {{.tone}} answer about {{.topic}}.
+
+{{.input}}
+`
+}
@@ -0,0 +1,204 @@
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultMaxFileSize is the largest file ReadFiles includes by default
+const DefaultMaxFileSize = 256 * 1024
+
+// FileInput is a file read for inclusion in a prompt
+type FileInput struct {
+	Path    string // As matched, with forward slashes
+	Content string
+}
+
+// SkippedFile is a matched file that was left out, with the reason
+type SkippedFile struct {
+	Path   string
+	Reason string
+}
+
+// ReadFiles reads the files named by paths, directories and glob patterns
+// Patterns use path.Match syntax per segment, plus "**" for any number of
+// directories. Directories and globs skip files excluded by .gitignore; a
+// file named explicitly is always read. Binary files and files larger than
+// maxSize (when positive) are skipped. Each file is read once, in the order
+// the patterns match it.
+func ReadFiles(patterns []string, maxSize int64) ([]FileInput, []SkippedFile, error) {
+	ignore := newGitIgnore()
+	seen := make(map[string]bool)
+	var files []FileInput
+	var skipped []SkippedFile
+
+	add := func(name string, size int64) error {
+		name = filepath.ToSlash(name)
+		if seen[name] {
+			return nil
+		}
+		seen[name] = true
+
+		if maxSize > 0 && size > maxSize {
+			skipped = append(skipped, SkippedFile{name, fmt.Sprintf("larger than %s", FormatSize(maxSize))})
+			return nil
+		}
+		data, err := os.ReadFile(filepath.FromSlash(name))
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		if isBinary(data) {
+			skipped = append(skipped, SkippedFile{name, "binary"})
+			return nil
+		}
+		files = append(files, FileInput{Path: name, Content: string(data)})
+		return nil
+	}
+
+	for _, pattern := range patterns {
+		matched := 0
+		// walk adds the matching files under root, skipping directories whose
+		// paths have maxDepth or more segments when maxDepth is positive
+		walk := func(root string, match func(string) bool, maxDepth int) error {
+			return filepath.WalkDir(root, func(name string, d fs.DirEntry, err error) error {
+				if err != nil {
+					return err
+				}
+				if name != root && ignore.Ignored(name, d.IsDir()) {
+					if d.IsDir() {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				if d.IsDir() && maxDepth > 0 && name != "." && strings.Count(filepath.ToSlash(name), "/")+1 >= maxDepth {
+					return filepath.SkipDir
+				}
+				if d.IsDir() || !d.Type().IsRegular() || !match(filepath.ToSlash(name)) {
+					return nil
+				}
+				info, err := d.Info()
+				if err != nil {
+					return err
+				}
+				matched++
+				return add(name, info.Size())
+			})
+		}
+
+		var err error
+		if !hasMeta(pattern) {
+			info, statErr := os.Stat(pattern)
+			switch {
+			case errors.Is(statErr, fs.ErrNotExist):
+				return nil, nil, fmt.Errorf("%s does not exist", pattern)
+			case statErr != nil:
+				return nil, nil, statErr
+			case info.IsDir():
+				err = walk(pattern, func(string) bool { return true }, 0)
+			default:
+				matched++
+				err = add(pattern, info.Size())
+			}
+		} else {
+			clean := path.Clean(filepath.ToSlash(pattern))
+			maxDepth := 0
+			if !strings.Contains(clean, "**") {
+				maxDepth = strings.Count(clean, "/") + 1
+			}
+			base := filepath.FromSlash(globBase(clean))
+			if _, statErr := os.Stat(base); statErr == nil {
+				err = walk(base, func(name string) bool {
+					return MatchPath(clean, name)
+				}, maxDepth)
+			}
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if matched == 0 {
+			return nil, nil, fmt.Errorf("no files match %s", pattern)
+		}
+	}
+	return files, skipped, nil
+}
+
+// hasMeta reports whether pattern contains glob characters
+func hasMeta(pattern string) bool {
+	return strings.ContainsAny(pattern, `*?[`)
+}
+
+// globBase returns the leading directories of pattern that contain no glob characters
+func globBase(pattern string) string {
+	segments := strings.Split(pattern, "/")
+	var base []string
+	for _, s := range segments[:len(segments)-1] {
+		if hasMeta(s) {
+			break
+		}
+		base = append(base, s)
+	}
+	switch {
+	case len(base) == 0:
+		return "."
+	case len(base) == 1 && base[0] == "":
+		return "/"
+	}
+	return strings.Join(base, "/")
+}
+
+// isBinary reports whether data looks like a binary file rather than text
+func isBinary(data []byte) bool {
+	sample := data
+	if len(sample) > 8000 {
+		sample = sample[:8000]
+	}
+	return bytes.IndexByte(sample, 0) >= 0 || !utf8.Valid(data)
+}
+
+// EstimateTokens roughly estimates the number of tokens in text
+// Most tokenizers average about four characters per token for English and code
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
+
+// ParseSize parses a size such as "512", "100k" or "2MB" into bytes
+func ParseSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "B"), "I")
+	multiplier := int64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		multiplier = 1024
+	case strings.HasSuffix(s, "M"):
+		multiplier = 1024 * 1024
+	case strings.HasSuffix(s, "G"):
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid size %q (use e.g. 512k or 2M)", value)
+	}
+	return n * multiplier, nil
+}
+
+// FormatSize formats a byte count for display, e.g. "256 KB"
+func FormatSize(n int64) string {
+	switch {
+	case n >= 1024*1024:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/(1024*1024)), ".0") + " MB"
+	case n >= 1024:
+		return strings.TrimSuffix(fmt.Sprintf("%.1f", float64(n)/1024), ".0") + " KB"
+	}
+	return fmt.Sprintf("%d B", n)
+}
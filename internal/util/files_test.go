@@ -0,0 +1,121 @@
+package util
+
+import (
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setupRepo runs the test in a new git repository holding files
+func setupRepo(t *testing.T, files map[string]string) {
+	t.Helper()
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir(".git", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, files)
+}
+
+func paths(files []FileInput) []string {
+	var names []string
+	for _, f := range files {
+		names = append(names, f.Path)
+	}
+	return names
+}
+
+func TestReadFiles(t *testing.T) {
+	setupRepo(t, map[string]string{
+		".gitignore":           "*.log\nvendor/\n",
+		"main.go":              "package main",
+		"app.log":              "log",
+		"src/a.go":             "package src",
+		"src/b.txt":            "text",
+		"src/sub/c.go":         "package sub",
+		"src/sub/deep/d.go":    "package deep",
+		"vendor/lib/e.go":      "package lib",
+		"src/sub/generated.go": "package sub",
+	})
+
+	tests := []struct {
+		patterns []string
+		want     []string
+	}{
+		{[]string{"main.go"}, []string{"main.go"}},
+		{[]string{"*.go"}, []string{"main.go"}},
+		{[]string{"src/*.go"}, []string{"src/a.go"}},
+		{[]string{"src/*/*.go"}, []string{"src/sub/c.go", "src/sub/generated.go"}},
+		{[]string{"src/**/*.go"}, []string{"src/a.go", "src/sub/c.go", "src/sub/deep/d.go", "src/sub/generated.go"}},
+		{[]string{"**/*.go"}, []string{"main.go", "src/a.go", "src/sub/c.go", "src/sub/deep/d.go", "src/sub/generated.go"}},
+		// An ignored directory is walked when named as the base of a pattern
+		{[]string{"vendor/**/*.go"}, []string{"vendor/lib/e.go"}},
+		{[]string{"src/sub"}, []string{"src/sub/c.go", "src/sub/deep/d.go", "src/sub/generated.go"}},
+		// A file named explicitly is read even when ignored
+		{[]string{"app.log"}, []string{"app.log"}},
+		// Each file is read once, in the order the patterns match it
+		{[]string{"src/b.txt", "src/*"}, []string{"src/b.txt", "src/a.go"}},
+	}
+	for _, tt := range tests {
+		files, _, err := ReadFiles(tt.patterns, 0)
+		if err != nil {
+			t.Errorf("ReadFiles(%q): %v", tt.patterns, err)
+			continue
+		}
+		if got := paths(files); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ReadFiles(%q) = %q, want %q", tt.patterns, got, tt.want)
+		}
+	}
+
+	for _, pattern := range []string{"missing.go", "*.rs", "**/e.go"} {
+		if _, _, err := ReadFiles([]string{pattern}, 0); err == nil {
+			t.Errorf("ReadFiles(%q) should fail", pattern)
+		}
+	}
+}
+
+func TestReadFilesPrunesBelowPatternDepth(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+	setupRepo(t, map[string]string{
+		"src/a.go":           "package src",
+		"src/locked/deep.go": "package locked",
+	})
+	if err := os.Chmod("src/locked", 0); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chmod("src/locked", 0755) })
+
+	// src/*.go can't match anything in src/locked, so it is never opened
+	files, _, err := ReadFiles([]string{"src/*.go"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(files); !reflect.DeepEqual(got, []string{"src/a.go"}) {
+		t.Errorf("files = %q", got)
+	}
+	if _, _, err := ReadFiles([]string{"src/**/*.go"}, 0); err == nil {
+		t.Error("src/**/*.go should have walked into src/locked")
+	}
+}
+
+func TestReadFilesSkipped(t *testing.T) {
+	setupRepo(t, map[string]string{
+		"small.txt":  "hello",
+		"large.txt":  strings.Repeat("x", 2048),
+		"binary.dat": "PK\x00\x03",
+	})
+
+	files, skipped, err := ReadFiles([]string{"*"}, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(files); !reflect.DeepEqual(got, []string{"small.txt"}) {
+		t.Errorf("files = %q", got)
+	}
+	want := []SkippedFile{{"binary.dat", "binary"}, {"large.txt", "larger than 1 KB"}}
+	if !reflect.DeepEqual(skipped, want) {
+		t.Errorf("skipped = %+v, want %+v", skipped, want)
+	}
+}
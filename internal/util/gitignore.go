@@ -0,0 +1,178 @@
+package util
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ignoreRule is one pattern from a .gitignore file
+type ignoreRule struct {
+	pattern string // Slash-separated, relative to the .gitignore's directory
+	negate  bool
+	dirOnly bool
+}
+
+// matches reports whether rel, relative to the rule's directory, is covered
+func (r ignoreRule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	return MatchPath(r.pattern, rel)
+}
+
+// parseIgnoreRule parses a .gitignore line, returning false for blanks and comments
+func parseIgnoreRule(line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\!`) || strings.HasPrefix(line, `\#`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// A pattern without a slash (other than a trailing one) matches at any depth
+	if strings.HasPrefix(line, "/") {
+		line = line[1:]
+	} else if !strings.Contains(line, "/") {
+		line = "**/" + line
+	}
+	rule.pattern = line
+	return rule, true
+}
+
+// gitIgnore answers whether paths are excluded by the .gitignore files of
+// the repository they are in. Files outside a git repository are never ignored.
+type gitIgnore struct {
+	rules map[string][]ignoreRule // By directory
+	roots map[string]string       // Repository root by directory, "" if none
+}
+
+func newGitIgnore() *gitIgnore {
+	return &gitIgnore{
+		rules: make(map[string][]ignoreRule),
+		roots: make(map[string]string),
+	}
+}
+
+// Ignored reports whether name is excluded by .gitignore. The .git directory
+// itself is always ignored. Parent directories are not checked, so callers
+// walking a tree should skip ignored directories.
+func (g *gitIgnore) Ignored(name string, isDir bool) bool {
+	abs, err := filepath.Abs(name)
+	if err != nil {
+		return false
+	}
+	if isDir && filepath.Base(abs) == ".git" {
+		return true
+	}
+
+	dir := filepath.Dir(abs)
+	root := g.repoRoot(dir)
+	if root == "" {
+		return false
+	}
+
+	// Apply .gitignore files from the root down; the last match wins
+	var dirs []string
+	for d := dir; ; d = filepath.Dir(d) {
+		dirs = append(dirs, d)
+		if d == root || filepath.Dir(d) == d {
+			break
+		}
+	}
+	ignored := false
+	for i := len(dirs) - 1; i >= 0; i-- {
+		rel, err := filepath.Rel(dirs[i], abs)
+		if err != nil {
+			continue
+		}
+		rel = filepath.ToSlash(rel)
+		for _, rule := range g.load(dirs[i]) {
+			if rule.matches(rel, isDir) {
+				ignored = !rule.negate
+			}
+		}
+	}
+	return ignored
+}
+
+// repoRoot returns the closest directory at or above dir containing .git
+func (g *gitIgnore) repoRoot(dir string) string {
+	if root, ok := g.roots[dir]; ok {
+		return root
+	}
+	root := ""
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+		root = dir
+	} else if parent := filepath.Dir(dir); parent != dir {
+		root = g.repoRoot(parent)
+	}
+	g.roots[dir] = root
+	return root
+}
+
+// load reads and caches the rules in dir's .gitignore
+func (g *gitIgnore) load(dir string) []ignoreRule {
+	if rules, ok := g.rules[dir]; ok {
+		return rules
+	}
+	var rules []ignoreRule
+	if f, err := os.Open(filepath.Join(dir, ".gitignore")); err == nil {
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreRule(scanner.Text()); ok {
+				rules = append(rules, rule)
+			}
+		}
+		f.Close()
+	}
+	g.rules[dir] = rules
+	return rules
+}
+
+// MatchPath reports whether a slash-separated name matches pattern
+// Segments are matched with path.Match, and a "**" segment matches any
+// number of directories, including none.
+func MatchPath(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			pattern = pattern[1:]
+			if len(pattern) == 0 {
+				return true
+			}
+			for i := 0; i <= len(name); i++ {
+				if matchSegments(pattern, name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
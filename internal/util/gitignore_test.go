@@ -0,0 +1,122 @@
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestParseIgnoreRule(t *testing.T) {
+	tests := []struct {
+		line string
+		want ignoreRule
+		ok   bool
+	}{
+		{"", ignoreRule{}, false},
+		{"# comment", ignoreRule{}, false},
+		{"!", ignoreRule{}, false},
+		{"*.log", ignoreRule{pattern: "**/*.log"}, true},
+		{"*.log  ", ignoreRule{pattern: "**/*.log"}, true},
+		{"/build", ignoreRule{pattern: "build"}, true},
+		{"docs/*.md", ignoreRule{pattern: "docs/*.md"}, true},
+		{"node_modules/", ignoreRule{pattern: "**/node_modules", dirOnly: true}, true},
+		{"/out/", ignoreRule{pattern: "out", dirOnly: true}, true},
+		{"!keep.log", ignoreRule{pattern: "**/keep.log", negate: true}, true},
+		{`\!important`, ignoreRule{pattern: "**/!important"}, true},
+		{`\#hash`, ignoreRule{pattern: "**/#hash"}, true},
+	}
+	for _, tt := range tests {
+		got, ok := parseIgnoreRule(tt.line)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseIgnoreRule(%q) = %+v, %v; want %+v, %v", tt.line, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMatchPath(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "cmd/main.go", false},
+		{"**/*.go", "main.go", true},
+		{"**/*.go", "internal/cli/root.go", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/y/c", false},
+		{"a/**", "a/x/y", true},
+		{"build", "build", true},
+		{"build", "src/build", false},
+		{"**/build", "src/build", true},
+		{"docs/*.md", "docs/guide.md", true},
+		{"docs/*.md", "docs/api/ref.md", false},
+		{"file?.txt", "file1.txt", true},
+		{"[ab].txt", "c.txt", false},
+	}
+	for _, tt := range tests {
+		if got := MatchPath(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchPath(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+// writeTree creates files (with content "x") under the current directory
+func writeTree(t *testing.T, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestGitIgnore(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir(".git", 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeTree(t, map[string]string{
+		".gitignore":     "*.log\n!keep.log\n/build\ncache/\n",
+		"src/.gitignore": "generated.go\n!debug.log\n",
+	})
+
+	tests := []struct {
+		name  string
+		isDir bool
+		want  bool
+	}{
+		{"app.log", false, true},
+		{"src/deep/app.log", false, true},
+		{"keep.log", false, false},
+		{"src/debug.log", false, false}, // Negated by the nested .gitignore
+		{"debug.log", false, true},      // The nested negation doesn't reach up
+		{"build", true, true},
+		{"src/build", true, false}, // Anchored to the root
+		{"cache", true, true},
+		{"src/cache", true, true},
+		{"cache", false, false}, // Directory-only rule
+		{"src/generated.go", false, true},
+		{"generated.go", false, false},
+		{"main.go", false, false},
+		{".git", true, true},
+	}
+	ignore := newGitIgnore()
+	for _, tt := range tests {
+		if got := ignore.Ignored(tt.name, tt.isDir); got != tt.want {
+			t.Errorf("Ignored(%q, dir=%v) = %v, want %v", tt.name, tt.isDir, got, tt.want)
+		}
+	}
+}
+
+func TestGitIgnoreOutsideRepository(t *testing.T) {
+	t.Chdir(t.TempDir())
+	writeTree(t, map[string]string{".gitignore": "*.log\n"})
+	if newGitIgnore().Ignored("app.log", false) {
+		t.Error("a .gitignore outside a repository was applied")
+	}
+}
@@ -0,0 +1,52 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNoTerminal is returned when a hidden prompt is needed but no terminal is available
+var ErrNoTerminal = errors.New("no terminal available for hidden input")
+
+// ReadPassword prompts on stderr and reads a line from the terminal without echoing it
+// The controlling terminal is used directly, so this works while stdin is a pipe
+func ReadPassword(prompt string) (string, error) {
+	tty, err := openTTY()
+	if err != nil {
+		return "", ErrNoTerminal
+	}
+	defer tty.Close()
+
+	fmt.Fprint(os.Stderr, prompt)
+	line, err := readHidden(tty)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read input: %w", err)
+	}
+	return line, nil
+}
+
+// readLine reads up to a newline one byte at a time so nothing past the line is consumed
+func readLine(r io.Reader) (string, error) {
+	var sb strings.Builder
+	buf := make([]byte, 1)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			sb.WriteByte(buf[0])
+		}
+		if err == io.EOF && sb.Len() > 0 {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimRight(sb.String(), "\r"), nil
+}
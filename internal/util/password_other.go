@@ -0,0 +1,15 @@
+//go:build !(darwin || dragonfly || freebsd || netbsd || openbsd || linux || aix || solaris || windows)
+
+package util
+
+import "os"
+
+// openTTY is not supported on this platform
+func openTTY() (*os.File, error) {
+	return nil, ErrNoTerminal
+}
+
+// readHidden is not supported on this platform
+func readHidden(tty *os.File) (string, error) {
+	return "", ErrNoTerminal
+}
@@ -0,0 +1,31 @@
+//go:build windows
+
+package util
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// openTTY opens the console input buffer
+func openTTY() (*os.File, error) {
+	return os.OpenFile("CONIN$", os.O_RDWR, 0)
+}
+
+// readHidden reads a line from the console with echo turned off
+func readHidden(tty *os.File) (string, error) {
+	handle := windows.Handle(tty.Fd())
+	var old uint32
+	if err := windows.GetConsoleMode(handle, &old); err != nil {
+		return "", err
+	}
+
+	hidden := old&^windows.ENABLE_ECHO_INPUT | windows.ENABLE_PROCESSED_INPUT | windows.ENABLE_LINE_INPUT
+	if err := windows.SetConsoleMode(handle, hidden); err != nil {
+		return "", err
+	}
+	defer windows.SetConsoleMode(handle, old)
+
+	return readLine(tty)
+}
@@ -0,0 +1,107 @@
+package util
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Prompter asks the user questions
+// Interactive commands take a Prompter so they can be driven by scripted input
+type Prompter interface {
+	// Ask reads a line of input, returning def when the answer is empty
+	Ask(prompt, def string) (string, error)
+	// AskSecret reads a line of input without echoing it where possible
+	AskSecret(prompt string) (string, error)
+	// Confirm asks a yes/no question, returning def when the answer is empty
+	Confirm(prompt string, def bool) (bool, error)
+}
+
+// LinePrompter is a Prompter that reads answers line by line
+// An exhausted input returns io.EOF
+type LinePrompter struct {
+	in       *bufio.Reader
+	out      io.Writer
+	terminal bool // input is typed, so the terminal echoes newlines and can hide secrets
+}
+
+// NewLinePrompter reads answers from in and writes prompts to out
+// Secrets are read as ordinary lines, which suits scripted input
+func NewLinePrompter(in io.Reader, out io.Writer) *LinePrompter {
+	return &LinePrompter{in: bufio.NewReader(in), out: out}
+}
+
+// NewTerminalPrompter prompts on stdout and reads from stdin
+// Secrets are read without echo when stdin is a terminal
+func NewTerminalPrompter() *LinePrompter {
+	p := NewLinePrompter(os.Stdin, os.Stdout)
+	p.terminal = IsTerminal(os.Stdin)
+	return p
+}
+
+// Ask implements Prompter
+func (p *LinePrompter) Ask(prompt, def string) (string, error) {
+	if def != "" {
+		prompt = fmt.Sprintf("%s [%s]", prompt, def)
+	}
+	answer, err := p.readLine(prompt + ": ")
+	if err != nil {
+		return "", err
+	}
+	if answer == "" {
+		return def, nil
+	}
+	return answer, nil
+}
+
+// AskSecret implements Prompter
+func (p *LinePrompter) AskSecret(prompt string) (string, error) {
+	if p.terminal {
+		answer, err := ReadPassword(prompt + ": ")
+		return strings.TrimSpace(answer), err
+	}
+	return p.readLine(prompt + ": ")
+}
+
+// Confirm implements Prompter
+func (p *LinePrompter) Confirm(prompt string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+	for {
+		answer, err := p.readLine(fmt.Sprintf("%s [%s]: ", prompt, hint))
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return def, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer y or n.")
+	}
+}
+
+// readLine prints prompt and reads one trimmed line
+// A final line without a newline is returned; an empty input is io.EOF
+func (p *LinePrompter) readLine(prompt string) (string, error) {
+	fmt.Fprint(p.out, prompt)
+	line, err := p.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if !p.terminal || err != nil {
+		// Scripted answers are not echoed, so end the prompt line ourselves
+		fmt.Fprintln(p.out)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
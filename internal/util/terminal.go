@@ -0,0 +1,34 @@
+package util
+
+import (
+	"os"
+	"strconv"
+
+	"github.com/mattn/go-isatty"
+)
+
+// DefaultTerminalWidth is used when the terminal size cannot be detected
+const DefaultTerminalWidth = 120
+
+// IsTerminal reports whether the given file is attached to a terminal
+func IsTerminal(f *os.File) bool {
+	return isatty.IsTerminal(f.Fd()) || isatty.IsCygwinTerminal(f.Fd())
+}
+
+// TerminalWidth returns the width of the terminal attached to stdout
+// $COLUMNS takes precedence, falling back to DefaultTerminalWidth when
+// stdout is not a terminal or the size cannot be read
+func TerminalWidth() int {
+	if cols, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && cols > 0 {
+		return cols
+	}
+
+	if !IsTerminal(os.Stdout) {
+		return DefaultTerminalWidth
+	}
+
+	if width := terminalWidth(os.Stdout); width > 0 {
+		return width
+	}
+	return DefaultTerminalWidth
+}
@@ -0,0 +1,10 @@
+//go:build !unix && !windows
+
+package util
+
+import "os"
+
+// terminalWidth is not supported on this platform
+func terminalWidth(f *os.File) int {
+	return 0
+}
@@ -0,0 +1,10 @@
+//go:build linux || aix || solaris
+
+package util
+
+import "golang.org/x/sys/unix"
+
+const (
+	ioctlReadTermios  = unix.TCGETS
+	ioctlWriteTermios = unix.TCSETS
+)